@@ -1,27 +1,226 @@
 package algorithm
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"math/rand"
 	"strings"
+	"time"
 
 	"leistungsnachweis-graphiker/problem"
 )
 
+// searches short cycles and reports every improvement and its progress to updates. the last event
+// before Solve closes updates is marked as final. Solve returns when the search is finished or
+// because ctx was cancelled. it returns the error of ctx in the latter case, or an error if the
+// algorithm is not able to solve the problem
 type Algorithm interface {
-	Solve(adjacency problem.Adjacency, updates chan problem.Cycle)
-	Stop()
+	Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error
 	String() string
 }
 
-func FromString(algorithmName string) (Algorithm, error) {
+// an event sent by an algorithm to report its progress. events either carry a new shortest cycle
+// or only statistics, e.g. the number of calculations per second of bruteforce
+type Progress struct {
+	// the new shortest cycle, nil if the event only reports statistics
+	Cycle problem.Cycle
+
+	// distance of the shortest cycle found so far, math.MaxFloat64 if there is none
+	Distance float64
+
+	// lower bound of the distance of the shortest cycle known to the algorithm, 0 if there is none
+	LowerBound float64
+
+	// number of iterations done so far, e.g. kicks of lin-kernighan or generations of genetic
+	Iteration int
+
+	// what the algorithm is currently doing, e.g. the stage of a chain
+	Phase string
+
+	// algorithm-specific statistics, e.g. the temperature of simulated annealing
+	Stats map[string]float64
+
+	// state of the search that can be passed to Checkpointer.Restore to continue the search later,
+	// nil unless a checkpoint is due
+	State json.RawMessage
+
+	// set on the last event before updates is closed. optimal tells if the shortest cycle is
+	// proven to be the shortest one, i.e. an exact algorithm finished without being cancelled
+	Final   bool
+	Optimal bool
+}
+
+// implemented by algorithms that accept parameters, e.g. "heldkarp:memory=1024"
+type Configurable interface {
+	Configure(key, value string) error
+}
+
+// implemented by algorithms that can tell if they are able to solve a problem before starting
+type Validator interface {
+	Validate(adjacency problem.Adjacency) error
+}
+
+// implemented by algorithms that need the coordinates of the points, not only their distances
+type Geometric interface {
+	SetPoints(points []problem.Point)
+}
+
+// implemented by algorithms that keep pheromones on the edges. snapshots of the pheromones are sent
+// to the given channel, e.g. to render a heat-map, and dropped if it is not ready to receive them
+type PheromoneSource interface {
+	SetPheromones(pheromones chan<- [][]float64)
+}
+
+// implemented by algorithms that can improve a given cycle instead of constructing their own
+type WarmStarter interface {
+	SetInitialCycle(cycle problem.Cycle)
+}
+
+// implemented by algorithms whose search can be continued from a checkpoint. the state of the search
+// is attached to a progress event every interval, and to the final event if the search was cancelled
+type Checkpointer interface {
+	SetCheckpointInterval(interval time.Duration)
+	Restore(state json.RawMessage) error
+}
+
+// implemented by stochastic algorithms. every random decision is drawn from the given source, so that
+// two runs with the same seed find the same cycles
+type Randomized interface {
+	SetRandom(random *rand.Rand)
+}
+
+// implemented by algorithms that solve asymmetric problems, whose distance from one point to another
+// differs from the distance back. all other algorithms assume symmetric distances
+type Asymmetric interface {
+	SupportsAsymmetric() bool
+}
+
+// implemented by exact algorithms, tells if the last cycle sent is proven to be the shortest one,
+// i.e. the search finished without being cancelled
+type Exact interface {
+	Optimal() bool
+}
+
+// creates an algorithm from a specification of the form "name[:key=value[:key=value...]]".
+// algorithms separated by "+" run one after another, each starting from the shortest cycle so far.
+// algorithms separated by "," run concurrently as a portfolio, e.g. "nn+2opt,annealing,heldkarp"
+func FromString(spec string) (Algorithm, error) {
+	if members := strings.Split(spec, ","); len(members) > 1 {
+		algorithms := make([]Algorithm, len(members))
+		for i, member := range members {
+			algorithm, err := FromString(strings.TrimSpace(member))
+			if err != nil {
+				return nil, err
+			}
+			algorithms[i] = algorithm
+		}
+		return NewPortfolio(algorithms...), nil
+	}
+
+	if stages := strings.Split(spec, "+"); len(stages) > 1 {
+		algorithms := make([]Algorithm, len(stages))
+		for i, stage := range stages {
+			algorithm, err := fromSingleString(strings.TrimSpace(stage))
+			if err != nil {
+				return nil, err
+			}
+			algorithms[i] = algorithm
+		}
+		return NewChain(algorithms...), nil
+	}
+
+	return fromSingleString(spec)
+}
+
+// creates an algorithm like FromString, but refuses it if it is not able to solve a problem with the
+// given distances, i.e. if the distances are asymmetric and the algorithm, or any member of a
+// portfolio or chain, assumes symmetric ones
+func FromStringFor(spec string, adjacency problem.Adjacency) (Algorithm, error) {
+	algorithm, err := FromString(spec)
+	if err != nil {
+		return nil, err
+	}
+	if !adjacency.Symmetric() && !supportsAsymmetric(algorithm) {
+		return nil, fmt.Errorf("%s does not support asymmetric problems", algorithm)
+	}
+	return algorithm, nil
+}
+
+// tells if an algorithm solves asymmetric problems, see Asymmetric
+func supportsAsymmetric(algorithm Algorithm) bool {
+	asymmetric, ok := algorithm.(Asymmetric)
+	return ok && asymmetric.SupportsAsymmetric()
+}
+
+// creates a single algorithm from a specification of the form "name[:key=value[:key=value...]]"
+func fromSingleString(spec string) (Algorithm, error) {
+	parts := strings.Split(spec, ":")
+	algorithmName := parts[0]
+
+	var algorithm Algorithm
 	switch alg := strings.ToLower(algorithmName); alg {
-	//case "mst":
-	//	return NewMst(), nil
 	case "bruteforce":
-		return NewBruteForce(), nil
+		algorithm = NewBruteForce()
 	case "heldkarp":
-		return NewHeldKarp(), nil
+		algorithm = NewHeldKarp()
+	case "branchandbound":
+		algorithm = NewBranchAndBound()
+	case "mst":
+		algorithm = NewMst()
+	case "christofides":
+		algorithm = NewChristofides()
+	case "2opt":
+		algorithm = NewTwoOpt()
+	case "localsearch":
+		algorithm = NewLocalSearch()
+	case "linkernighan":
+		algorithm = NewLinKernighan()
+	case "ils":
+		algorithm = NewIteratedLocalSearch()
+	case "gls":
+		algorithm = NewGuidedLocalSearch()
+	case "annealing":
+		algorithm = NewSimulatedAnnealing()
+	case "genetic":
+		algorithm = NewGenetic()
+	case "antcolony":
+		algorithm = NewAntColony()
+	case "tabu":
+		algorithm = NewTabuSearch()
+	case "nn", "nearestneighbour":
+		algorithm = NewNearestNeighbour()
+	case "greedy":
+		algorithm = NewGreedyEdge()
+	case "nearestinsertion":
+		algorithm = NewNearestInsertion()
+	case "cheapestinsertion":
+		algorithm = NewCheapestInsertion()
+	case "farthestinsertion":
+		algorithm = NewFarthestInsertion()
+	case "convexhull":
+		algorithm = NewConvexHullInsertion()
 	default:
 		return nil, fmt.Errorf("algorithm not found: %s", algorithmName)
 	}
+
+	// apply parameters, if any
+	for _, parameter := range parts[1:] {
+		configurable, ok := algorithm.(Configurable)
+		if !ok {
+			return nil, fmt.Errorf("algorithm %s does not accept parameters", algorithm)
+		}
+
+		keyValue := strings.SplitN(parameter, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("invalid parameter for %s: %s", algorithm, parameter)
+		}
+
+		err := configurable.Configure(strings.ToLower(keyValue[0]), keyValue[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return algorithm, nil
 }
@@ -0,0 +1,59 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math/rand"
+	"testing"
+)
+
+// generates an asymmetric problem with n points, whose distances in both directions are drawn
+// independently from [1, 100]
+func asymmetricProblem(n int, seed int64) *problem.Problem {
+	r := rand.New(rand.NewSource(seed))
+	adjacency := make(problem.Adjacency, n)
+	for i := range adjacency {
+		adjacency[i] = make([]float64, n)
+		for j := range adjacency[i] {
+			if i != j {
+				adjacency[i][j] = float64(1 + r.Intn(100))
+			}
+		}
+	}
+	return &problem.Problem{Points: make([]problem.Point, n), Adjacency: adjacency}
+}
+
+// generates a problem whose points form a one-way ring, going to the previous point costs 1 and every
+// other edge costs 10. the shortest cycle follows the ring and has length n, while the cycle that
+// visits the points in ascending order has length 10n
+func oneWayRing(n int) *problem.Problem {
+	adjacency := make(problem.Adjacency, n)
+	for i := range adjacency {
+		adjacency[i] = make([]float64, n)
+		for j := range adjacency[i] {
+			if i != j {
+				adjacency[i][j] = 10
+			}
+		}
+		adjacency[i][(i+n-1)%n] = 1
+	}
+	return &problem.Problem{Points: make([]problem.Point, n), Adjacency: adjacency}
+}
+
+func TestFromStringFor(t *testing.T) {
+	symmetric := randomProblem(10, 1).Adjacency
+	asymmetric := asymmetricProblem(10, 1).Adjacency
+
+	for _, spec := range []string{"heldkarp", "bruteforce:parallel=true", "nn", "cheapestinsertion+bruteforce", "nn,heldkarp"} {
+		if _, err := FromStringFor(spec, asymmetric); err != nil {
+			t.Fatalf("%s: %s", spec, err)
+		}
+	}
+	for _, spec := range []string{"2opt", "christofides", "greedy", "nn+2opt", "heldkarp,annealing"} {
+		if _, err := FromStringFor(spec, asymmetric); err == nil {
+			t.Fatalf("%s: expected error for an asymmetric problem", spec)
+		}
+		if _, err := FromStringFor(spec, symmetric); err != nil {
+			t.Fatalf("%s: %s", spec, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// cooling schedules of simulated annealing
+const (
+	// the temperature is multiplied by the cooling rate after every epoch
+	ScheduleGeometric = "geometric"
+
+	// the temperature decreases linearly to zero over all iterations
+	ScheduleLinear = "linear"
+
+	// geometric, but the temperature is raised again when the shortest cycle did not improve for a while
+	ScheduleAdaptive = "adaptive"
+)
+
+const (
+	// default factor by which the temperature is multiplied after every epoch
+	DefaultCoolingRate = 0.95
+
+	// default number of epochs without improvement after which the adaptive schedule reheats
+	DefaultReheatAfter = 50
+
+	// the number of iterations in an epoch is this factor times the number of points
+	annealingEpochFactor = 100
+
+	// the geometric schedule is frozen once the temperature fell below this fraction of the start
+	annealingFreeze = 1e-3
+
+	// the adaptive schedule reheats to this fraction of the start temperature
+	annealingReheat = 0.5
+)
+
+// improves a cycle by reversing random segments. reversals that make the cycle shorter are always
+// accepted, those that make it longer by delta are accepted with probability exp(-delta/temperature).
+// the temperature follows the configured schedule until it freezes or the iterations are used up
+type SimulatedAnnealing struct {
+	warmStart
+	randomness
+	Schedule         string
+	StartTemperature float64
+	CoolingRate      float64
+	Iterations       int
+	ReheatAfter      int
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewSimulatedAnnealing() *SimulatedAnnealing {
+	return &SimulatedAnnealing{
+		Schedule:         ScheduleGeometric,
+		CoolingRate:      DefaultCoolingRate,
+		ReheatAfter:      DefaultReheatAfter,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of simulated annealing, supported keys are:
+//   - schedule: geometric, linear or adaptive
+//   - temperature: the start temperature, 0 to derive it from the problem
+//   - cooling: factor by which the temperature is multiplied after every epoch
+//   - iterations: number of iterations after which the algorithm finishes, 0 for no limit
+//   - reheat: number of epochs without improvement after which the adaptive schedule reheats
+func (a *SimulatedAnnealing) Configure(key, value string) error {
+	switch key {
+	case "schedule":
+		schedule := strings.ToLower(value)
+		if schedule != ScheduleGeometric && schedule != ScheduleLinear && schedule != ScheduleAdaptive {
+			return fmt.Errorf("unknown cooling schedule: %s", value)
+		}
+		a.Schedule = schedule
+	case "temperature":
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil || temperature < 0 {
+			return fmt.Errorf("invalid start temperature: %s", value)
+		}
+		a.StartTemperature = temperature
+	case "cooling":
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 || rate >= 1 {
+			return fmt.Errorf("invalid cooling rate: %s", value)
+		}
+		a.CoolingRate = rate
+	case "iterations":
+		iterations, err := strconv.Atoi(value)
+		if err != nil || iterations < 0 {
+			return fmt.Errorf("invalid number of iterations: %s", value)
+		}
+		a.Iterations = iterations
+	case "reheat":
+		reheat, err := strconv.Atoi(value)
+		if err != nil || reheat < 1 {
+			return fmt.Errorf("invalid number of epochs for reheating: %s", value)
+		}
+		a.ReheatAfter = reheat
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *SimulatedAnnealing) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		random := a.random()
+		t := newTour(a.startCycle(adjacency))
+		distance := t.distance(adjacency)
+		a.shortestDistance = distance
+		a.shortestCycle = t.Cycle()
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance})
+
+		startTemperature := a.StartTemperature
+		if startTemperature == 0 {
+			startTemperature = initialTemperature(adjacency, t, random)
+		}
+
+		// the linear and the adaptive schedule need a limit to finish
+		epoch := annealingEpochFactor * n
+		iterations := a.Iterations
+		if iterations == 0 && a.Schedule != ScheduleGeometric {
+			iterations = epoch * int(math.Ceil(math.Log(annealingFreeze)/math.Log(a.CoolingRate)))
+		}
+		log.Printf("solving problemset with %d entries using simulated annealing, %s schedule, start temperature %f",
+			n, a.Schedule, startTemperature)
+
+		temperature := startTemperature
+		sinceImprovement := 0
+		iteration := 0
+		for n >= 4 && !cancelled(ctx) && (iterations == 0 || iteration < iterations) {
+			iteration++
+			i, j := twoRandomPoints(t, random)
+			from, to := t.cycle[i], t.cycle[j]
+			before, after := t.prev(from), t.next(to)
+			delta := adjacency[before][to] + adjacency[from][after] - adjacency[before][from] - adjacency[to][after]
+
+			if delta < 0 || random.Float64() < math.Exp(-delta/temperature) {
+				t.reverse(from, to)
+				distance += delta
+
+				if distance < a.shortestDistance-epsilon {
+					a.shortestDistance = distance
+					a.shortestCycle = t.Cycle()
+					throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance, Iteration: iteration, Stats: a.stats(temperature)})
+					sinceImprovement = 0
+				}
+			}
+
+			if iteration%epoch == 0 {
+				throttled.Report(Progress{Iteration: iteration, Stats: a.stats(temperature)})
+			}
+
+			if a.Schedule == ScheduleLinear {
+				temperature = startTemperature * (1 - float64(iteration)/float64(iterations))
+				continue
+			}
+
+			if iteration%epoch != 0 {
+				continue
+			}
+
+			// end of an epoch, also get rid of rounding errors that accumulated in the distance
+			distance = t.distance(adjacency)
+			temperature *= a.CoolingRate
+			if a.Schedule == ScheduleAdaptive {
+				if sinceImprovement++; sinceImprovement >= a.ReheatAfter {
+					temperature = startTemperature * annealingReheat
+					sinceImprovement = 0
+				}
+			} else if temperature < startTemperature*annealingFreeze {
+				break
+			}
+		}
+
+		throttled.Finish(Progress{Iteration: iteration, Stats: a.stats(temperature)})
+	}
+
+	return ctx.Err()
+}
+
+// returns the statistics of simulated annealing for a progress event
+func (a *SimulatedAnnealing) stats(temperature float64) map[string]float64 {
+	return map[string]float64{"temperature": temperature}
+}
+
+// picks the positions of two random points on the tour so that i < j and the segment
+// from i to j is neither empty nor the whole tour
+func twoRandomPoints(t *tour, random *rand.Rand) (int, int) {
+	n := len(t.cycle)
+	for {
+		i, j := random.Intn(n), random.Intn(n)
+		if i > j {
+			i, j = j, i
+		}
+		if i != j && j-i < n-1 {
+			return i, j
+		}
+	}
+}
+
+// derives the start temperature from random reversals of the tour, so that a reversal that makes
+// the tour longer by the average amount is accepted with a probability of 50%
+func initialTemperature(adjacency problem.Adjacency, t *tour, random *rand.Rand) float64 {
+	n := len(t.cycle)
+	if n < 4 {
+		return 1
+	}
+
+	var sum float64
+	var count int
+	for k := 0; k < 1000; k++ {
+		i, j := twoRandomPoints(t, random)
+		from, to := t.cycle[i], t.cycle[j]
+		before, after := t.prev(from), t.next(to)
+		delta := adjacency[before][to] + adjacency[from][after] - adjacency[before][from] - adjacency[to][after]
+		if delta > 0 {
+			sum += delta
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 1
+	}
+	return sum / float64(count) / math.Ln2
+}
+
+func (a SimulatedAnnealing) String() string {
+	return "Simulated Annealing"
+}
@@ -0,0 +1,51 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestSimulatedAnnealing(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewSimulatedAnnealing(), p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestSimulatedAnnealingSchedules(t *testing.T) {
+	p := randomProblem(14, 5)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	for _, schedule := range []string{ScheduleGeometric, ScheduleLinear, ScheduleAdaptive} {
+		a := NewSimulatedAnnealing()
+		a.Schedule = schedule
+		if d := solveProblem(t, a, p); d > 1.02*optimal {
+			t.Fatalf("%s: distance %f is not within 2%% of optimal distance %f", schedule, d, optimal)
+		}
+	}
+}
+
+func TestSimulatedAnnealingConfigure(t *testing.T) {
+	a, err := FromString("annealing:schedule=linear:temperature=100:iterations=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	annealing := a.(*SimulatedAnnealing)
+	if annealing.Schedule != ScheduleLinear || annealing.StartTemperature != 100 || annealing.Iterations != 5000 {
+		t.Fatalf("parameters not applied: %+v", annealing)
+	}
+
+	if _, err := FromString("annealing:schedule=exponential"); err == nil {
+		t.Fatalf("expected error for unknown schedule")
+	}
+}
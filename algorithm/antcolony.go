@@ -0,0 +1,420 @@
+package algorithm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// variants of the ant colony optimization
+const (
+	// ant colony system, ants prefer the best edge and only the shortest cycle deposits pheromone
+	VariantAntColonySystem = "acs"
+
+	// max-min ant system, the best ant of each iteration deposits pheromone, which is kept in bounds
+	VariantMaxMinAntSystem = "mmas"
+)
+
+const (
+	DefaultAnts         = 20
+	DefaultAlpha        = 1.0
+	DefaultBeta         = 3.0
+	DefaultEvaporation  = 0.1
+	DefaultExploitation = 0.9
+	DefaultIterations   = 1000
+
+	// number of iterations between two snapshots of the pheromones
+	DefaultSnapshotInterval = 10
+
+	// number of nearest neighbours an ant considers before looking at all points
+	antCandidates = 15
+
+	// weight of the local pheromone update of the ant colony system
+	antLocalEvaporation = 0.1
+)
+
+// lets a colony of ants construct cycles in parallel. each ant goes from point to point, choosing
+// the next point by the pheromone on the edge and the inverse of its distance, weighted by alpha
+// and beta. afterwards pheromone evaporates and is deposited on the edges of the best cycles
+type AntColony struct {
+	warmStart
+	checkpoints
+	randomness
+	resume           *antColonyState
+	Variant          string
+	Ants             int
+	Alpha            float64
+	Beta             float64
+	Evaporation      float64
+	Exploitation     float64
+	Iterations       int
+	SnapshotInterval int
+	Pheromones       chan<- [][]float64
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+// state of the ant colony at the start of an iteration
+type antColonyState struct {
+	Iteration  int           `json:"iteration"`
+	Initial    float64       `json:"initial"`
+	Pheromones [][]float64   `json:"pheromones"`
+	Shortest   problem.Cycle `json:"shortest"`
+}
+
+func NewAntColony() *AntColony {
+	return &AntColony{
+		Variant:          VariantAntColonySystem,
+		Ants:             DefaultAnts,
+		Alpha:            DefaultAlpha,
+		Beta:             DefaultBeta,
+		Evaporation:      DefaultEvaporation,
+		Exploitation:     DefaultExploitation,
+		Iterations:       DefaultIterations,
+		SnapshotInterval: DefaultSnapshotInterval,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of the ant colony, supported keys are:
+//   - variant: acs or mmas
+//   - ants: size of the colony
+//   - alpha: weight of the pheromone
+//   - beta: weight of the inverse distance
+//   - evaporation: share of the pheromone that evaporates in every iteration
+//   - exploitation: probability that an ant of the ant colony system takes the best edge
+//   - iterations: number of iterations after which the algorithm finishes, 0 for no limit
+func (a *AntColony) Configure(key, value string) error {
+	var err error
+	switch key {
+	case "variant":
+		variant := strings.ToLower(value)
+		if variant != VariantAntColonySystem && variant != VariantMaxMinAntSystem {
+			return fmt.Errorf("unknown ant colony variant: %s", value)
+		}
+		a.Variant = variant
+	case "ants":
+		a.Ants, err = strconv.Atoi(value)
+		if err != nil || a.Ants < 1 {
+			return fmt.Errorf("invalid number of ants: %s", value)
+		}
+	case "alpha":
+		a.Alpha, err = strconv.ParseFloat(value, 64)
+		if err != nil || a.Alpha < 0 {
+			return fmt.Errorf("invalid alpha: %s", value)
+		}
+	case "beta":
+		a.Beta, err = strconv.ParseFloat(value, 64)
+		if err != nil || a.Beta < 0 {
+			return fmt.Errorf("invalid beta: %s", value)
+		}
+	case "evaporation":
+		a.Evaporation, err = strconv.ParseFloat(value, 64)
+		if err != nil || a.Evaporation <= 0 || a.Evaporation > 1 {
+			return fmt.Errorf("invalid evaporation: %s", value)
+		}
+	case "exploitation":
+		a.Exploitation, err = strconv.ParseFloat(value, 64)
+		if err != nil || a.Exploitation < 0 || a.Exploitation > 1 {
+			return fmt.Errorf("invalid exploitation: %s", value)
+		}
+	case "iterations":
+		a.Iterations, err = strconv.Atoi(value)
+		if err != nil || a.Iterations < 0 {
+			return fmt.Errorf("invalid number of iterations: %s", value)
+		}
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+// sets the channel that receives the snapshots of the pheromones
+func (a *AntColony) SetPheromones(pheromones chan<- [][]float64) {
+	a.Pheromones = pheromones
+}
+
+func (a *AntColony) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using %s, %d ants, alpha %.2f, beta %.2f, evaporation %.2f",
+		n, a, a.Ants, a.Alpha, a.Beta, a.Evaporation)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		neighbours := nearestNeighbours(adjacency, antCandidates)
+		a.startCheckpoints()
+		state := a.resumeState(n)
+
+		// the initial cycle, nearest neighbour by default, determines the initial amount of pheromone
+		seed := a.startCycle(adjacency)
+		if state != nil {
+			seed = state.Shortest
+		}
+		a.shortestCycle = seed
+		a.shortestDistance = cycleDistance(adjacency, seed)
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance})
+
+		initial := 1 / (float64(n) * a.shortestDistance)
+		maxPheromone, minPheromone := a.pheromoneBounds(n)
+		if a.Variant == VariantMaxMinAntSystem {
+			initial = maxPheromone
+		}
+		pheromones := make([][]float64, n)
+		for i := range pheromones {
+			pheromones[i] = make([]float64, n)
+			for j := range pheromones[i] {
+				pheromones[i][j] = initial
+			}
+		}
+
+		// or the pheromones of a checkpoint
+		iteration := 0
+		if state != nil {
+			iteration, initial, pheromones = state.Iteration, state.Initial, state.Pheromones
+			log.Printf("resuming the %s at iteration %d", a, iteration)
+		}
+
+		// every ant has its own source of randomness, so that the cycles do not depend on the worker
+		// that constructs them
+		workers := runtime.GOMAXPROCS(0)
+		sources := make([]*rand.Rand, a.Ants)
+		for ant := range sources {
+			sources[ant] = deriveRandom(a.random())
+		}
+
+		weights := make([][]float64, n)
+		for i := range weights {
+			weights[i] = make([]float64, n)
+		}
+
+		for n >= 4 && !cancelled(ctx) && (a.Iterations == 0 || iteration < a.Iterations) {
+			if a.checkpointDue() {
+				throttled.Checkpoint(Progress{Iteration: iteration, State: a.state(iteration, initial, pheromones)})
+			}
+
+			iteration++
+
+			// weight of every edge, pheromone^alpha * (1/distance)^beta
+			for i := range weights {
+				for j := range weights[i] {
+					weights[i][j] = math.Pow(pheromones[i][j], a.Alpha) * math.Pow(1/math.Max(adjacency[i][j], epsilon), a.Beta)
+				}
+			}
+
+			// ants construct their cycles in parallel, the pheromones are not changed meanwhile
+			cycles := make([]problem.Cycle, a.Ants)
+			jobs := make(chan int, a.Ants)
+			for ant := range cycles {
+				jobs <- ant
+			}
+			close(jobs)
+
+			wg := sync.WaitGroup{}
+			wg.Add(workers)
+			for w := 0; w < workers; w++ {
+				go func() {
+					defer wg.Done()
+					for ant := range jobs {
+						cycles[ant] = a.construct(weights, neighbours, sources[ant])
+					}
+				}()
+			}
+			wg.Wait()
+
+			// shortest cycle of this iteration
+			iterationBest, iterationDistance := cycles[0], math.MaxFloat64
+			for _, cycle := range cycles {
+				if distance := cycleDistance(adjacency, cycle); distance < iterationDistance {
+					iterationBest, iterationDistance = cycle, distance
+				}
+			}
+			if iterationDistance < a.shortestDistance-epsilon {
+				a.shortestCycle = iterationBest
+				a.shortestDistance = iterationDistance
+				maxPheromone, minPheromone = a.pheromoneBounds(n)
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Iteration: iteration})
+			}
+
+			if a.Variant == VariantMaxMinAntSystem {
+				// evaporate everywhere, deposit on the best cycle of the iteration or,
+				// every tenth iteration, on the shortest cycle so far
+				deposit, distance := iterationBest, iterationDistance
+				if iteration%10 == 0 {
+					deposit, distance = a.shortestCycle, a.shortestDistance
+				}
+				for i := range pheromones {
+					for j := range pheromones[i] {
+						pheromones[i][j] *= 1 - a.Evaporation
+					}
+				}
+				depositPheromone(pheromones, deposit, 1/distance, 1)
+				for i := range pheromones {
+					for j := range pheromones[i] {
+						pheromones[i][j] = math.Max(minPheromone, math.Min(maxPheromone, pheromones[i][j]))
+					}
+				}
+			} else {
+				// local update on the edges the ants used, then global update on the shortest cycle
+				for _, cycle := range cycles {
+					depositPheromone(pheromones, cycle, antLocalEvaporation*initial, 1-antLocalEvaporation)
+				}
+				depositPheromone(pheromones, a.shortestCycle, a.Evaporation/a.shortestDistance, 1-a.Evaporation)
+			}
+
+			if a.Pheromones != nil && iteration%a.SnapshotInterval == 0 {
+				snapshot := make([][]float64, n)
+				for i := range snapshot {
+					snapshot[i] = make([]float64, n)
+					copy(snapshot[i], pheromones[i])
+				}
+				select {
+				case a.Pheromones <- snapshot:
+				default:
+				}
+			}
+
+			throttled.Report(Progress{Iteration: iteration, Stats: map[string]float64{"iteration best": iterationDistance}})
+		}
+
+		progress := Progress{Iteration: iteration}
+		if a.finalCheckpoint(ctx) {
+			progress.State = a.state(iteration, initial, pheromones)
+		}
+		throttled.Finish(progress)
+	}
+
+	return ctx.Err()
+}
+
+// continues with the pheromones of a checkpoint in the next search
+func (a *AntColony) Restore(state json.RawMessage) error {
+	var s antColonyState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return fmt.Errorf("invalid state of %s: %s", a, err)
+	}
+	a.resume = &s
+	return nil
+}
+
+// returns the state to resume from and forgets it, nil if there is none or it does not fit the problem
+func (a *AntColony) resumeState(n int) *antColonyState {
+	state := a.resume
+	a.resume = nil
+	if state == nil {
+		return nil
+	}
+
+	fits := isPermutation(state.Shortest, n) && len(state.Pheromones) == n && state.Initial > 0
+	for _, row := range state.Pheromones {
+		fits = fits && len(row) == n
+	}
+	if !fits {
+		log.Printf("state of %s does not fit the problem, starting from scratch", a)
+		return nil
+	}
+	return state
+}
+
+// serializes the pheromones at the start of an iteration
+func (a *AntColony) state(iteration int, initial float64, pheromones [][]float64) json.RawMessage {
+	return marshalState(antColonyState{
+		Iteration:  iteration,
+		Initial:    initial,
+		Pheromones: pheromones,
+		Shortest:   a.shortestCycle,
+	})
+}
+
+func (a AntColony) String() string {
+	if a.Variant == VariantMaxMinAntSystem {
+		return "Max-Min Ant System"
+	}
+	return "Ant Colony System"
+}
+
+// bounds of the pheromone of the max-min ant system, derived from the shortest cycle so far
+func (a *AntColony) pheromoneBounds(n int) (float64, float64) {
+	maxPheromone := 1 / (a.Evaporation * a.shortestDistance)
+	return maxPheromone, maxPheromone / (2 * float64(n))
+}
+
+// lets a single ant construct a cycle, starting at a random point
+func (a *AntColony) construct(weights [][]float64, neighbours [][]int, random *rand.Rand) problem.Cycle {
+	n := len(weights)
+	visited := make([]bool, n)
+	cycle := make(problem.Cycle, 0, n)
+	current := random.Intn(n)
+	probabilities := make([]float64, 0, n)
+	candidates := make([]int, 0, n)
+
+	for {
+		visited[current] = true
+		cycle = append(cycle, current)
+		if len(cycle) == n {
+			return cycle
+		}
+
+		// prefer the nearest neighbours, fall back to all points if they were all visited
+		candidates = candidates[:0]
+		for _, p := range neighbours[current] {
+			if !visited[p] {
+				candidates = append(candidates, p)
+			}
+		}
+		if len(candidates) == 0 {
+			for p := range visited {
+				if !visited[p] {
+					candidates = append(candidates, p)
+				}
+			}
+		}
+
+		// the ant colony system exploits the best edge with a certain probability
+		if a.Variant == VariantAntColonySystem && random.Float64() < a.Exploitation {
+			next := candidates[0]
+			for _, p := range candidates {
+				if weights[current][p] > weights[current][next] {
+					next = p
+				}
+			}
+			current = next
+			continue
+		}
+
+		// otherwise the next point is chosen proportionally to the weights
+		var sum float64
+		probabilities = probabilities[:0]
+		for _, p := range candidates {
+			sum += weights[current][p]
+			probabilities = append(probabilities, sum)
+		}
+		threshold := random.Float64() * sum
+		next := candidates[len(candidates)-1]
+		for k, p := range probabilities {
+			if threshold < p {
+				next = candidates[k]
+				break
+			}
+		}
+		current = next
+	}
+}
+
+// multiplies the pheromone on the edges of the cycle by factor and adds amount, in both directions
+func depositPheromone(pheromones [][]float64, cycle problem.Cycle, amount, factor float64) {
+	for i, p := range cycle {
+		q := cycle[(i+1)%len(cycle)]
+		pheromones[p][q] = factor*pheromones[p][q] + amount
+		pheromones[q][p] = pheromones[p][q]
+	}
+}
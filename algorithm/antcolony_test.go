@@ -0,0 +1,97 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAntColony(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	a := NewAntColony()
+	a.Iterations = 20
+	if d := solveProblem(t, a, p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestAntColonyVariants(t *testing.T) {
+	p := randomProblem(14, 8)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	for _, variant := range []string{VariantAntColonySystem, VariantMaxMinAntSystem} {
+		a := NewAntColony()
+		a.Variant = variant
+		a.Iterations = 200
+		if d := solveProblem(t, a, p); d > 1.05*optimal {
+			t.Fatalf("%s: distance %f is not within 5%% of optimal distance %f", variant, d, optimal)
+		}
+	}
+}
+
+func TestAntColonyPheromones(t *testing.T) {
+	p := randomProblem(20, 9)
+	pheromones := make(chan [][]float64, 10)
+
+	a := NewAntColony()
+	a.Iterations = 50
+	a.Pheromones = pheromones
+	solveProblem(t, a, p)
+	close(pheromones)
+
+	snapshots := 0
+	for snapshot := range pheromones {
+		if len(snapshot) != 20 || len(snapshot[0]) != 20 {
+			t.Fatalf("snapshot has wrong dimensions")
+		}
+		snapshots++
+	}
+	if snapshots != 5 {
+		t.Fatalf("expected 5 snapshots, got %d", snapshots)
+	}
+}
+
+func TestAntColonyResume(t *testing.T) {
+	p := randomProblem(12, 14)
+
+	// a checkpoint at the start of every iteration
+	a := NewAntColony()
+	a.Iterations = 30
+	a.SetCheckpointInterval(time.Nanosecond)
+	events := checkpointEvents(t, context.Background(), a, p)
+	if events[20].Iteration != 20 {
+		t.Fatalf("expected a checkpoint in iteration 20, got %d", events[20].Iteration)
+	}
+
+	// the resumed colony continues with the pheromones and the shortest cycle of the checkpoint
+	resumed := NewAntColony()
+	resumed.Iterations = 30
+	if err := resumed.Restore(events[20].State); err != nil {
+		t.Fatal(err)
+	}
+	u := make(chan Progress, 10)
+	go resumed.Solve(context.Background(), p.Adjacency, u)
+	var first, last Progress
+	for progress := range u {
+		if first.Cycle == nil {
+			first = progress
+		}
+		last = progress
+	}
+	if first.Distance > events[20].Distance+1e-9 {
+		t.Fatalf("resumed with distance %f, the checkpoint has %f", first.Distance, events[20].Distance)
+	}
+	if last.Iteration != 30 {
+		t.Fatalf("expected 30 iterations in total, got %d", last.Iteration)
+	}
+}
@@ -0,0 +1,154 @@
+package algorithm
+
+import (
+	"context"
+	"math"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// number of subgradient-iterations used by HeldKarpBound
+const heldKarpBoundIterations = 1000
+
+// calculates the trivial lower bound, the length of a minimum spanning tree. removing an edge from
+// any cycle leaves a spanning tree, so no cycle is shorter than the minimum spanning tree
+func MinimumSpanningTreeBound(adjacency problem.Adjacency) float64 {
+	var bound float64
+	for _, e := range minimumSpanningTree(adjacency) {
+		bound += e.dist
+	}
+	return bound
+}
+
+// calculates the held-karp lower bound, a 1-tree whose edges are weighted by penalties that are
+// improved by subgradient ascent. upperBound is the length of a known cycle, e.g. nearest neighbour.
+// a cancelled calculation returns the best bound found so far, which is a lower bound as well
+func HeldKarpBound(ctx context.Context, adjacency problem.Adjacency, upperBound float64) float64 {
+	bound, _ := heldKarpBound(ctx, adjacency, upperBound, heldKarpBoundIterations)
+	return math.Max(bound, MinimumSpanningTreeBound(adjacency))
+}
+
+// calculates by how many percent a distance exceeds a lower bound
+func Gap(distance, lowerBound float64) float64 {
+	if lowerBound <= 0 || distance <= lowerBound {
+		return 0
+	}
+	return (distance - lowerBound) / lowerBound * 100
+}
+
+// calculates a minimum 1-tree of the adjacency, where every distance (i, j) is increased by the
+// penalties of i and j. a 1-tree is a minimum spanning tree over the points [1, n-1] plus the two
+// shortest edges that connect point 0 to it. returns the penalized cost of the 1-tree and the
+// degree of every point
+func oneTree(adjacency problem.Adjacency, penalties []float64) (float64, []int) {
+	n := len(adjacency)
+	degrees := make([]int, n)
+	if n < 3 {
+		return 0, degrees
+	}
+
+	cost := func(i, j int) float64 { return adjacency[i][j] + penalties[i] + penalties[j] }
+
+	// prim's algorithm on [1, n-1]
+	inTree := make([]bool, n)
+	minEdge := make([]float64, n)
+	parent := make([]int, n)
+	for i := range minEdge {
+		minEdge[i] = math.MaxFloat64
+	}
+	minEdge[1] = 0
+	parent[1] = -1
+
+	var total float64
+	for k := 1; k < n; k++ {
+		next := -1
+		for i := 1; i < n; i++ {
+			if !inTree[i] && (next == -1 || minEdge[i] < minEdge[next]) {
+				next = i
+			}
+		}
+
+		inTree[next] = true
+		total += minEdge[next]
+		if parent[next] != -1 {
+			degrees[next]++
+			degrees[parent[next]]++
+		}
+
+		for i := 1; i < n; i++ {
+			if !inTree[i] && cost(next, i) < minEdge[i] {
+				minEdge[i] = cost(next, i)
+				parent[i] = next
+			}
+		}
+	}
+
+	// connect point 0 with its two shortest edges
+	first, second := -1, -1
+	for i := 1; i < n; i++ {
+		if first == -1 || cost(0, i) < cost(0, first) {
+			first, second = i, first
+		} else if second == -1 || cost(0, i) < cost(0, second) {
+			second = i
+		}
+	}
+	total += cost(0, first) + cost(0, second)
+	degrees[0] = 2
+	degrees[first]++
+	degrees[second]++
+
+	return total, degrees
+}
+
+// calculates the held-karp lower bound by subgradient ascent on the penalties of the 1-tree.
+// upperBound is the length of a known cycle and is used to choose the step size. returns the
+// best bound and the penalties it was found with. stops early when ctx is cancelled
+func heldKarpBound(ctx context.Context, adjacency problem.Adjacency, upperBound float64, iterations int) (float64, []float64) {
+	n := len(adjacency)
+	penalties := make([]float64, n)
+	bestPenalties := make([]float64, n)
+	bestBound := math.Inf(-1)
+	if n < 3 {
+		return cycleDistance(adjacency, identityCycle(n)), bestPenalties
+	}
+
+	lambda := 2.0
+	sinceImprovement := 0
+	for iteration := 0; iteration < iterations && !cancelled(ctx); iteration++ {
+		cost, degrees := oneTree(adjacency, penalties)
+
+		var penaltySum float64
+		for _, p := range penalties {
+			penaltySum += p
+		}
+		bound := cost - 2*penaltySum
+
+		if bound > bestBound {
+			bestBound = bound
+			copy(bestPenalties, penalties)
+			sinceImprovement = 0
+		} else if sinceImprovement++; sinceImprovement >= n/2+5 {
+			lambda /= 2
+			sinceImprovement = 0
+		}
+
+		// every degree is two, the 1-tree is a cycle and the bound is exact
+		var norm float64
+		for _, d := range degrees {
+			norm += float64((d - 2) * (d - 2))
+		}
+		if norm == 0 || lambda < 1e-6 {
+			break
+		}
+
+		step := lambda * (upperBound - bound) / norm
+		if step <= 0 {
+			break
+		}
+		for i, d := range degrees {
+			penalties[i] += step * float64(d-2)
+		}
+	}
+
+	return bestBound, bestPenalties
+}
@@ -0,0 +1,61 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestLowerBounds(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		p := randomProblem(12, seed)
+		optimal := solveProblem(t, NewHeldKarp(), p)
+
+		mst := MinimumSpanningTreeBound(p.Adjacency)
+		heldKarp := HeldKarpBound(context.Background(), p.Adjacency, cycleDistance(p.Adjacency, NearestNeighbour(p.Adjacency, 0)))
+		if mst > heldKarp+epsilon || heldKarp > optimal+epsilon {
+			t.Fatalf("bounds are not ordered: mst %f, held-karp %f, optimal %f", mst, heldKarp, optimal)
+		}
+	}
+}
+
+func TestLowerBoundsSquare(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+	}
+
+	p := problem.NewProblem(points)
+	if mst := MinimumSpanningTreeBound(p.Adjacency); math.Round(mst) != 150 {
+		t.Fatalf("wrong minimum spanning tree bound: %f", mst)
+	}
+	if heldKarp := HeldKarpBound(context.Background(), p.Adjacency, 200); math.Round(heldKarp) != 200 {
+		t.Fatalf("wrong held-karp bound: %f", heldKarp)
+	}
+}
+
+func TestLowerBoundsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a cancelled calculation falls back to the minimum spanning tree bound
+	p := randomProblem(50, 1)
+	if heldKarp, mst := HeldKarpBound(ctx, p.Adjacency, math.MaxFloat64), MinimumSpanningTreeBound(p.Adjacency); heldKarp != mst {
+		t.Fatalf("cancelled held-karp bound %f is not the minimum spanning tree bound %f", heldKarp, mst)
+	}
+}
+
+func TestGap(t *testing.T) {
+	if gap := Gap(110, 100); math.Abs(gap-10) > epsilon {
+		t.Fatalf("wrong gap: %f", gap)
+	}
+	if gap := Gap(100, 0); gap != 0 {
+		t.Fatalf("gap without a lower bound must be 0, got %f", gap)
+	}
+	if gap := Gap(100, 100+epsilon); gap != 0 {
+		t.Fatalf("gap must not be negative, got %f", gap)
+	}
+}
@@ -0,0 +1,193 @@
+package algorithm
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// number of subgradient-iterations used to calculate the penalties at the root
+const branchAndBoundIterations = 1000
+
+type BranchAndBound struct {
+	warmStart
+	optimal          bool
+	nodes            uint64
+	rootBound        float64
+	lastReport       time.Time
+	penalties        []float64
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewBranchAndBound() *BranchAndBound {
+	return &BranchAndBound{
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+func (a *BranchAndBound) Optimal() bool {
+	return a.optimal
+}
+
+// extends paths that start at point 0 depth-first. a path is pruned as soon as its length plus a
+// lower bound for the remaining points is not shorter than the shortest cycle found so far. the
+// bound is a 1-tree over the remaining points, using penalties from held-karp's subgradient ascent
+func (a *BranchAndBound) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	a.optimal = false
+	a.nodes = 0
+	a.rootBound = 0
+	startTime := time.Now()
+	a.lastReport = startTime
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using branch and bound", n)
+
+	best := newIncumbent(updates)
+
+	// seed the incumbent with the initial cycle or a quick heuristic
+	if n > 0 {
+		seed := a.startCycle(adjacency)
+		best.Offer(seed, cycleDistance(adjacency, seed))
+	}
+
+	// with less than four points, every cycle is a rotation or mirror of the first one
+	finished := true
+	if n >= 4 {
+		// the subgradient ascent returns no bound if it is cancelled before its first iteration
+		a.rootBound, a.penalties = heldKarpBound(ctx, adjacency, best.Distance(), branchAndBoundIterations)
+		a.rootBound = math.Max(a.rootBound, MinimumSpanningTreeBound(adjacency))
+		log.Printf("lower bound at the root: %f", a.rootBound)
+
+		cycle := make(problem.Cycle, n)
+		visited := make([]bool, n)
+		visited[0] = true
+		finished = a.search(ctx, adjacency, cycle, visited, 1, 0, best)
+	}
+
+	a.shortestDistance = best.Distance()
+	a.shortestCycle = best.Cycle()
+	a.optimal = finished
+	log.Printf("explored %d nodes in %s", a.nodes, time.Since(startTime))
+
+	if n > 0 {
+		progress := a.progress()
+		progress.Optimal = a.optimal
+		best.Finish(progress)
+	}
+	return ctx.Err()
+}
+
+// places a point at position depth of the cycle, children are visited nearest first. returns false
+// if the search was cancelled before every child was either visited or pruned
+func (a *BranchAndBound) search(ctx context.Context, adjacency problem.Adjacency, cycle problem.Cycle, visited []bool, depth int,
+	distance float64, best *incumbent) bool {
+	n := len(cycle)
+	last := cycle[depth-1]
+	a.nodes++
+
+	// report the explored nodes, check the time only every once in a while
+	if a.nodes&0x3ff == 0 && time.Since(a.lastReport) >= statisticsInterval {
+		a.lastReport = time.Now()
+		best.Report(a.progress())
+	}
+
+	if depth == n {
+		best.Offer(cycle, distance+adjacency[last][0])
+		return true
+	}
+
+	children := make([]int, 0, n-depth)
+	for p := 1; p < n; p++ {
+		if !visited[p] {
+			children = append(children, p)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return adjacency[last][children[i]] < adjacency[last][children[j]]
+	})
+
+	for _, p := range children {
+		if cancelled(ctx) {
+			return false
+		}
+
+		childDistance := distance + adjacency[last][p]
+		visited[p] = true
+		finished := true
+		if childDistance+a.completionBound(adjacency, p, visited) < best.Distance()-epsilon {
+			cycle[depth] = p
+			finished = a.search(ctx, adjacency, cycle, visited, depth+1, childDistance, best)
+		}
+		visited[p] = false
+		if !finished {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the explored nodes and the lower bound at the root as an event
+func (a *BranchAndBound) progress() Progress {
+	return Progress{LowerBound: a.rootBound, Iteration: int(a.nodes)}
+}
+
+// calculates a lower bound for the shortest path that starts at last, visits all remaining
+// points and ends at point 0. every distance (i, j) is increased by the penalties of i and j,
+// which increases the length of every such path by the same amount, so it is subtracted again
+func (a *BranchAndBound) completionBound(adjacency problem.Adjacency, last int, visited []bool) float64 {
+	cost := func(i, j int) float64 { return adjacency[i][j] + a.penalties[i] + a.penalties[j] }
+
+	remaining := make([]int, 0, len(visited))
+	for p, v := range visited {
+		if !v {
+			remaining = append(remaining, p)
+		}
+	}
+	if len(remaining) == 0 {
+		return adjacency[last][0]
+	}
+
+	// shortest edges that connect the remaining points to last and to 0
+	toLast, toStart := math.MaxFloat64, math.MaxFloat64
+	offset := a.penalties[last] + a.penalties[0]
+	for _, p := range remaining {
+		toLast = math.Min(toLast, cost(last, p))
+		toStart = math.Min(toStart, cost(p, 0))
+		offset += 2 * a.penalties[p]
+	}
+
+	// prim's algorithm on the remaining points
+	minEdge := make([]float64, len(remaining))
+	for i := range minEdge {
+		minEdge[i] = math.MaxFloat64
+	}
+	inTree := make([]bool, len(remaining))
+	mst := 0.0
+	minEdge[0] = 0
+	for range remaining {
+		next := -1
+		for i := range remaining {
+			if !inTree[i] && (next == -1 || minEdge[i] < minEdge[next]) {
+				next = i
+			}
+		}
+		inTree[next] = true
+		mst += minEdge[next]
+		for i := range remaining {
+			if !inTree[i] {
+				minEdge[i] = math.Min(minEdge[i], cost(remaining[next], remaining[i]))
+			}
+		}
+	}
+
+	return mst + toLast + toStart - offset
+}
+
+func (a BranchAndBound) String() string {
+	return "Branch and Bound"
+}
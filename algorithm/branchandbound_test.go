@@ -0,0 +1,87 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestBranchAndBound(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	b := NewBranchAndBound()
+	u := make(chan Progress, 10)
+
+	go b.Solve(context.Background(), p.Adjacency, u)
+
+	for {
+		progress, hasMore := <-u
+		if !hasMore {
+			break
+		}
+		if progress.Cycle != nil {
+			p.UpdateRoute(progress.Cycle)
+		}
+	}
+
+	if math.Round(p.ShortestDistance*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", p.ShortestDistance)
+	}
+}
+
+func TestBranchAndBoundCancelled(t *testing.T) {
+	p := randomProblem(30, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// cancelled before the held-karp bound, the final event still has the bound of the spanning tree
+	a := NewBranchAndBound()
+	u := make(chan Progress, 10)
+	go a.Solve(ctx, p.Adjacency, u)
+	var final Progress
+	for progress := range u {
+		final = progress
+	}
+	if mst := MinimumSpanningTreeBound(p.Adjacency); final.LowerBound < mst || math.IsInf(final.LowerBound, 0) {
+		t.Fatalf("lower bound %f is below the spanning tree %f", final.LowerBound, mst)
+	}
+	if final.Optimal || a.Optimal() {
+		t.Fatalf("cancelled search claims to be optimal")
+	}
+}
+
+func TestBranchAndBoundMatchesHeldKarp(t *testing.T) {
+	points := []problem.Point{
+		{X: 3, Y: 91}, {X: 74, Y: 12}, {X: 45, Y: 67}, {X: 18, Y: 30},
+		{X: 88, Y: 55}, {X: 61, Y: 94}, {X: 27, Y: 8}, {X: 96, Y: 81},
+		{X: 52, Y: 38}, {X: 9, Y: 59}, {X: 70, Y: 44}, {X: 35, Y: 15},
+		{X: 80, Y: 28}, {X: 22, Y: 76},
+	}
+	p := problem.NewProblem(points)
+
+	solve := func(a Algorithm) float64 {
+		u := make(chan Progress, 10)
+		go a.Solve(context.Background(), p.Adjacency, u)
+		for progress := range u {
+			if progress.Cycle != nil {
+				p.UpdateRoute(progress.Cycle)
+			}
+		}
+		return p.ShortestDistance
+	}
+
+	heldKarp := solve(NewHeldKarp())
+	branchAndBound := solve(NewBranchAndBound())
+
+	if math.Abs(heldKarp-branchAndBound) > 1e-9 {
+		t.Fatalf("distances differ: heldkarp=%f, branchandbound=%f", heldKarp, branchAndBound)
+	}
+}
@@ -1,39 +1,192 @@
 package algorithm
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"leistungsnachweis-graphiker/problem"
 )
 
+// checks every cycle. an initial cycle serves as the first shortest cycle, so that only shorter
+// cycles are reported
 type BruteForce struct {
-	running          bool
-	calculations     uint64
+	warmStart
+	checkpoints
+	resume  *bruteForceState
+	optimal bool
+
+	// fix the first point, skip mirrored cycles and search on all cpu-cores
+	Parallel bool
+
+	// mirrored cycles have the same distance, false for asymmetric problems
+	symmetric bool
+
+	calculations     uint64 // accessed atomically
+	resumed          uint64 // calculations of the checkpoint the search was resumed from
 	shortestDistance float64
 	shortestCycle    []int
 }
 
+// state of the search of bruteforce. the sequential search stores the counters of heap's algorithm,
+// the parallel search the prefixes that were searched completely
+type bruteForceState struct {
+	Parallel     bool          `json:"parallel"`
+	Calculations uint64        `json:"calculations"`
+	Shortest     problem.Cycle `json:"shortest"`
+
+	Points []int `json:"points,omitempty"`
+	C      []int `json:"c,omitempty"`
+	I      int   `json:"i,omitempty"`
+
+	Workers int   `json:"workers,omitempty"`
+	Done    []int `json:"done,omitempty"`
+}
+
 func NewBruteForce() *BruteForce {
 	return &BruteForce{
 		shortestDistance: math.MaxFloat64,
 	}
 }
 
-func (a *BruteForce) Stop() {
-	a.running = false
+func (a *BruteForce) Optimal() bool {
+	return a.optimal
+}
+
+// solves asymmetric problems, the parallel search does not skip mirrored cycles for them
+func (a *BruteForce) SupportsAsymmetric() bool {
+	return true
+}
+
+// sets the parameters of bruteforce, supported keys are:
+//   - parallel: true to use the parallel, symmetry-aware search
+func (a *BruteForce) Configure(key, value string) error {
+	switch key {
+	case "parallel":
+		parallel, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for parallel: %s", value)
+		}
+		a.Parallel = parallel
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
 }
 
 //  64.099.164
 // 132.215.492
-func (a *BruteForce) Solve(adjacency problem.Adjacency, updates chan problem.Cycle) {
-	// set state to running
-	a.running = true
-	log.Printf("solving problemset with %d entries using bruteforce", len(adjacency))
+func (a *BruteForce) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	a.optimal = false
+	atomic.StoreUint64(&a.calculations, 0)
+	a.symmetric = adjacency.Symmetric()
+	best := newIncumbent(updates)
+	if a.Parallel {
+		best.breakTies(a.symmetric)
+	}
+	if initial := a.validInitialCycle(adjacency); initial != nil {
+		best.Offer(initial, cycleDistance(adjacency, initial))
+	}
+	a.startCheckpoints()
+	a.resumed = 0
+	resume := a.resumeState(len(adjacency))
+	if resume != nil {
+		a.resumed = resume.Calculations
+		atomic.StoreUint64(&a.calculations, resume.Calculations)
+		best.Offer(resume.Shortest, cycleDistance(adjacency, resume.Shortest))
+	}
+
+	// report statistics until the search returns
+	startTime := time.Now()
+	reporting, cancel := context.WithCancel(ctx)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go a.report(reporting, best, startTime, &wg)
+
+	var state *bruteForceState
+	var err error
+	if a.Parallel {
+		state, err = a.solveParallel(ctx, adjacency, best, resume)
+	} else {
+		state, err = a.solveSequential(ctx, adjacency, best, resume)
+	}
+	cancel()
+	wg.Wait()
 
-	// start worker for statistics
-	go a.worker()
+	// finished, unless the search was cancelled
+	a.shortestDistance = best.Distance()
+	a.shortestCycle = best.Cycle()
+	a.optimal = err == nil
+	if len(adjacency) > 0 {
+		progress := a.progress(startTime)
+		progress.Optimal = a.optimal
+		if a.finalCheckpoint(ctx) {
+			progress.State = a.state(state, best)
+		}
+		best.Finish(progress)
+	}
+	return err
+}
+
+// continues the search of a checkpoint in the next search
+func (a *BruteForce) Restore(state json.RawMessage) error {
+	var s bruteForceState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return fmt.Errorf("invalid state of %s: %s", a, err)
+	}
+	a.resume = &s
+	return nil
+}
+
+// returns the state to resume from and forgets it, nil if there is none or it does not fit the problem
+// or the kind of search
+func (a *BruteForce) resumeState(n int) *bruteForceState {
+	state := a.resume
+	a.resume = nil
+	if state == nil {
+		return nil
+	}
+
+	fits := state.Parallel == a.Parallel && isPermutation(state.Shortest, n)
+	if a.Parallel {
+		fits = fits && state.Workers > 0
+	} else {
+		fits = fits && isPermutation(state.Points, n) && len(state.C) == n && state.I >= 0 && state.I <= n
+		for k, c := range state.C {
+			fits = fits && c >= 0 && c <= k
+		}
+	}
+	if !fits {
+		log.Printf("state of %s does not fit the problem, starting from scratch", a)
+		return nil
+	}
+	return state
+}
+
+// serializes the state of the search along with the shortest cycle so far
+func (a *BruteForce) state(state *bruteForceState, best *incumbent) json.RawMessage {
+	if state == nil || best.Cycle() == nil {
+		return nil
+	}
+	state.Parallel = a.Parallel
+	state.Calculations = atomic.LoadUint64(&a.calculations)
+	state.Shortest = best.Cycle()
+	return marshalState(state)
+}
+
+// checks every permutation of the points using heap's algorithm. returns the counters of heap's
+// algorithm at the point where the search stopped
+func (a *BruteForce) solveSequential(ctx context.Context, adjacency problem.Adjacency, best *incumbent,
+	resume *bruteForceState) (*bruteForceState, error) {
+	log.Printf("solving problemset with %d entries using bruteforce", len(adjacency))
 
 	// slice to permute
 	points := make([]int, len(adjacency))
@@ -51,14 +204,12 @@ func (a *BruteForce) Solve(adjacency problem.Adjacency, updates chan problem.Cyc
 		}
 	}
 
-	// found new shortest shortestCycle, set properties
-	shortestCycle := make([]int, len(points))
-	copy(shortestCycle, points)
-	a.shortestDistance = distance
-	a.shortestCycle = shortestCycle
-
-	// forward result to session
-	updates <- problem.Cycle(shortestCycle)
+	// found new shortest cycle, forward the result to session, unless the initial cycle is shorter
+	shortestDistance := best.Distance()
+	if len(points) > 0 && distance < shortestDistance {
+		shortestDistance = distance
+		best.Offer(points, distance)
+	}
 
 	// heap's algorithm
 	c := make([]int, len(adjacency))
@@ -69,8 +220,32 @@ func (a *BruteForce) Solve(adjacency problem.Adjacency, updates chan problem.Cyc
 	pointLength := len(points)
 	cLength := len(c)
 
+	// the context is only checked every once in a while, the calculations are published at the same time
+	var calculations uint64
 	i := 0
-	for i < cLength && a.running {
+
+	// or continue where the checkpoint left off
+	if resume != nil {
+		copy(points, resume.Points)
+		copy(c, resume.C)
+		i = resume.I
+		calculations = resume.Calculations
+		distance = cycleDistance(adjacency, points)
+		shortestDistance = best.Distance()
+		log.Printf("resuming bruteforce after %d calculations", calculations)
+	}
+
+	for i < cLength {
+		if calculations&0x3ff == 0 {
+			atomic.StoreUint64(&a.calculations, calculations)
+			if cancelled(ctx) {
+				break
+			}
+			if a.checkpointDue() {
+				best.Report(Progress{Iteration: int(calculations), State: a.state(sequentialState(points, c, i), best)})
+			}
+		}
+
 		if c[i] < i {
 			// which point to swap with
 			j := 0
@@ -100,31 +275,42 @@ func (a *BruteForce) Solve(adjacency problem.Adjacency, updates chan problem.Cyc
 			// we increase performance by a factor of two. performance increase on a intel core i7-8700k is
 			// up from 64.000.000 to 132.000.000 iterations per second
 
-			// subtract distances
-			distance -= adjacency[points[j]][points[jLeft]] +
+			// subtract distances, in the direction of the cycle. if i and j are neighbours, the edge
+			// between them is counted only once, as it is reversed by the swap
+			distance -= adjacency[points[jLeft]][points[j]] +
 				adjacency[points[j]][points[jRight]] +
-				adjacency[points[i]][points[iLeft]] +
+				adjacency[points[iLeft]][points[i]] +
 				adjacency[points[i]][points[iRight]]
+			if jRight == i {
+				distance += adjacency[points[j]][points[i]]
+			}
+			if iRight == j {
+				distance += adjacency[points[i]][points[j]]
+			}
 
 			// swap i with j
 			points[j], points[i] = points[i], points[j]
 
 			// add distances
-			distance += adjacency[points[j]][points[jLeft]] +
+			distance += adjacency[points[jLeft]][points[j]] +
 				adjacency[points[j]][points[jRight]] +
-				adjacency[points[i]][points[iLeft]] +
+				adjacency[points[iLeft]][points[i]] +
 				adjacency[points[i]][points[iRight]]
+			if jRight == i {
+				distance -= adjacency[points[j]][points[i]]
+			}
+			if iRight == j {
+				distance -= adjacency[points[i]][points[j]]
+			}
 
-			if distance < a.shortestDistance {
-				// found new shortest shortestCycle, set properties and forward the result
-				shortestCycle := make([]int, len(points))
-				copy(shortestCycle, points)
-				a.shortestDistance = distance
-				a.shortestCycle = shortestCycle
-				updates <- problem.Cycle(shortestCycle)
+			if distance < shortestDistance-epsilon {
+				// found new shortest cycle, the incumbent copies and forwards it along with its exact
+				// distance, the incremental distance accumulates rounding errors
+				shortestDistance = cycleDistance(adjacency, points)
+				best.Offer(points, shortestDistance)
 			}
 
-			a.calculations++
+			calculations++
 			c[i]++
 			i = 0
 		} else {
@@ -133,22 +319,212 @@ func (a *BruteForce) Solve(adjacency problem.Adjacency, updates chan problem.Cyc
 		}
 	}
 
-	// finished, close the channel and set state
-	close(updates)
-	a.running = false
+	atomic.StoreUint64(&a.calculations, calculations)
+	return sequentialState(points, c, i), ctx.Err()
 }
 
-func (a *BruteForce) worker() {
-	startTime := time.Now()
-	ticker := time.NewTicker(time.Second)
+// copies the counters of heap's algorithm
+func sequentialState(points, c []int, i int) *bruteForceState {
+	state := &bruteForceState{Points: make([]int, len(points)), C: make([]int, len(c)), I: i}
+	copy(state.Points, points)
+	copy(state.C, c)
+	return state
+}
+
+// reports the calculations per second every second until ctx is cancelled
+func (a *BruteForce) report(ctx context.Context, best *incumbent, startTime time.Time, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(statisticsInterval)
 	defer ticker.Stop()
-	for a.running {
-		<-ticker.C
-		cps := float64(a.calculations) / time.Since(startTime).Seconds()
-		log.Printf("calculations per second: %d", int64(cps))
+	for {
+		select {
+		case <-ticker.C:
+			best.Report(a.progress(startTime))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// returns the number of calculations so far and the calculations per second as an event
+func (a *BruteForce) progress(startTime time.Time) Progress {
+	calculations := atomic.LoadUint64(&a.calculations)
+	return Progress{
+		Iteration: int(calculations),
+		Stats: map[string]float64{
+			"calculations per second": math.Round(float64(calculations-a.resumed) / time.Since(startTime).Seconds()),
+		},
+	}
+}
+
+// searches all cycles that start at point 0, where each cycle and its mirror are only visited once.
+// the search-space is split into prefixes that are processed by a pool of workers, one per cpu-core.
+// workers share the shortest distance found so far to prune partial cycles that are already longer.
+// returns the prefixes that were searched completely
+func (a *BruteForce) solveParallel(ctx context.Context, adjacency problem.Adjacency, best *incumbent,
+	resume *bruteForceState) (*bruteForceState, error) {
+	n := len(adjacency)
+	workers := runtime.GOMAXPROCS(0)
+	log.Printf("solving problemset with %d entries using parallel bruteforce on %d workers", n, workers)
+
+	// the first cycle serves as the initial bound, unless the initial cycle is shorter
+	cycle := identityCycle(n)
+	if n > 0 {
+		best.Offer(cycle, cycleDistance(adjacency, cycle))
+	}
+
+	// the prefixes depend on the number of workers, a checkpoint keeps the prefixes it was made with
+	prefixWorkers := workers
+	if resume != nil {
+		prefixWorkers = resume.Workers
+	}
+	prefixes := bruteForcePrefixes(n, prefixWorkers)
+	done := make([]bool, len(prefixes))
+	if resume != nil {
+		for _, index := range resume.Done {
+			if index >= 0 && index < len(done) {
+				done[index] = true
+			}
+		}
+		log.Printf("resuming parallel bruteforce with %d of %d prefixes searched", len(resume.Done), len(prefixes))
+	}
+	mutex := sync.Mutex{}
+	state := func() *bruteForceState {
+		state := &bruteForceState{Workers: prefixWorkers, Done: make([]int, 0)}
+		for index, prefixDone := range done {
+			if prefixDone {
+				state.Done = append(state.Done, index)
+			}
+		}
+		return state
+	}
+
+	// with less than four points, every cycle is a rotation or mirror of the first one. mirrors of
+	// asymmetric cycles differ from the first one from three points on
+	if n >= 4 || (n == 3 && !a.symmetric) {
+		jobs := make(chan int)
+		wg := sync.WaitGroup{}
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				for index := range jobs {
+					a.searchPrefix(ctx, adjacency, prefixes[index], best)
+
+					// a prefix is only searched completely if the search was not cancelled
+					if cancelled(ctx) {
+						continue
+					}
+					mutex.Lock()
+					done[index] = true
+					if a.checkpointDue() {
+						best.Report(Progress{Iteration: int(atomic.LoadUint64(&a.calculations)), State: a.state(state(), best)})
+					}
+					mutex.Unlock()
+				}
+			}()
+		}
+
+		for index := range prefixes {
+			if cancelled(ctx) {
+				break
+			}
+			if !done[index] {
+				jobs <- index
+			}
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	return state(), ctx.Err()
+}
+
+// generates the prefixes that are distributed to the workers. a prefix is a sequence of distinct
+// points out of [1, n-1] that follows point 0, prefixes get longer until there are enough to keep
+// all workers busy
+func bruteForcePrefixes(n, workers int) [][]int {
+	prefixes := [][]int{{}}
+	for length := 0; length < n-3 && len(prefixes) < 8*workers; length++ {
+		longer := make([][]int, 0, len(prefixes)*(n-1-length))
+		for _, prefix := range prefixes {
+		NextPoint:
+			for p := 1; p < n; p++ {
+				for _, q := range prefix {
+					if p == q {
+						continue NextPoint
+					}
+				}
+				longerPrefix := make([]int, len(prefix)+1)
+				copy(longerPrefix, prefix)
+				longerPrefix[len(prefix)] = p
+				longer = append(longer, longerPrefix)
+			}
+		}
+		prefixes = longer
+	}
+	return prefixes
+}
+
+// searches all cycles that start with 0 followed by the prefix
+func (a *BruteForce) searchPrefix(ctx context.Context, adjacency problem.Adjacency, prefix []int, best *incumbent) {
+	n := len(adjacency)
+	cycle := make(problem.Cycle, n)
+	visited := make([]bool, n)
+	visited[0] = true
+
+	var distance float64
+	for i, p := range prefix {
+		cycle[i+1] = p
+		visited[p] = true
+		distance += adjacency[cycle[i]][p]
+	}
+
+	var calculations uint64
+	a.search(ctx, adjacency, cycle, visited, len(prefix)+1, distance, best, &calculations)
+	atomic.AddUint64(&a.calculations, calculations)
+}
+
+// depth-first search that places a point at position depth of the cycle. mirrored cycles of
+// symmetric problems are skipped by only accepting cycles whose second point is smaller than the last one
+func (a *BruteForce) search(ctx context.Context, adjacency problem.Adjacency, cycle problem.Cycle, visited []bool, depth int,
+	distance float64, best *incumbent, calculations *uint64) {
+	n := len(cycle)
+
+	// partial cycle is already longer than the shortest one, or loses the tie-break of the parallel search
+	if best.Prunes(cycle[:depth], distance) || (depth < n-2 && cancelled(ctx)) {
+		return
+	}
+
+	if depth == n {
+		*calculations++
+		distance += adjacency[cycle[n-1]][cycle[0]]
+		if !best.Prunes(cycle, distance) {
+			best.Offer(cycle, distance)
+		}
+		return
+	}
+
+	for p := 1; p < n; p++ {
+		if visited[p] {
+			continue
+		}
+
+		// the last point has to be greater than the second one
+		if a.symmetric && depth == n-1 && p < cycle[1] {
+			continue
+		}
+
+		visited[p] = true
+		cycle[depth] = p
+		a.search(ctx, adjacency, cycle, visited, depth+1, distance+adjacency[cycle[depth-1]][p], best, calculations)
+		visited[p] = false
 	}
 }
 
 func (a BruteForce) String() string {
+	if a.Parallel {
+		return "Bruteforce (parallel)"
+	}
 	return "Bruteforce"
 }
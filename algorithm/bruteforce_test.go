@@ -1,9 +1,11 @@
 package algorithm
 
 import (
+	"context"
 	"leistungsnachweis-graphiker/problem"
 	"math"
 	"testing"
+	"time"
 )
 
 func TestBruteForce(t *testing.T) {
@@ -17,19 +19,214 @@ func TestBruteForce(t *testing.T) {
 
 	p := problem.NewProblem(points)
 	b := NewBruteForce()
-	u := make(chan problem.Cycle, 10)
+	u := make(chan Progress, 10)
 
-	go b.Solve(p.Adjacency, u)
+	go b.Solve(context.Background(), p.Adjacency, u)
 
 	for {
-		cycle, hasMore := <-u
+		progress, hasMore := <-u
 		if !hasMore {
 			break
 		}
-		p.UpdateRoute(cycle)
+		if progress.Cycle != nil {
+			p.UpdateRoute(progress.Cycle)
+		}
 	}
 
 	if math.Round(p.ShortestDistance*100)/100 != 220.71 {
 		t.Fatalf("wrong distance: %f", p.ShortestDistance)
 	}
 }
+
+func TestBruteForceParallel(t *testing.T) {
+	points := []problem.Point{
+		{X: 3, Y: 91}, {X: 74, Y: 12}, {X: 45, Y: 67}, {X: 18, Y: 30},
+		{X: 88, Y: 55}, {X: 61, Y: 94}, {X: 27, Y: 8}, {X: 96, Y: 81},
+		{X: 52, Y: 38},
+	}
+	p := problem.NewProblem(points)
+
+	solve := func(a Algorithm, strict bool) float64 {
+		u := make(chan Progress, 10)
+		go a.Solve(context.Background(), p.Adjacency, u)
+
+		last := math.MaxFloat64
+		for progress := range u {
+			if progress.Cycle == nil {
+				continue
+			}
+			p.UpdateRoute(progress.Cycle)
+			if strict && p.ShortestDistance >= last {
+				t.Fatalf("%s sent a cycle that is not an improvement", a)
+			}
+			last = p.ShortestDistance
+		}
+		return p.ShortestDistance
+	}
+
+	sequential := solve(NewBruteForce(), false)
+	parallel := solve(&BruteForce{Parallel: true}, true)
+
+	if math.Abs(sequential-parallel) > 1e-9 {
+		t.Fatalf("distances differ: sequential=%f, parallel=%f", sequential, parallel)
+	}
+}
+
+func TestBruteForceParallelTies(t *testing.T) {
+	// a square grid has many shortest cycles of the same length
+	var points []problem.Point
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 3; y++ {
+			points = append(points, problem.Point{X: float64(10 * x), Y: float64(10 * y)})
+		}
+	}
+	p := problem.NewProblem(points)
+	index := make(map[problem.Point]int)
+	for i, point := range p.Points {
+		index[point] = i
+	}
+
+	// a shortest cycle and its images under the symmetries of the grid, the search starts with the
+	// one that comes last in the canonical form, so it finds shortest cycles that win the tie-break
+	shortest := [][2]float64{{0, 0}, {0, 1}, {0, 2}, {1, 2}, {2, 2}, {2, 1}, {2, 0}, {1, 0}, {1, 1}}
+	var initial problem.Cycle
+	for symmetry := 0; symmetry < 8; symmetry++ {
+		cycle := make(problem.Cycle, len(shortest))
+		for i, c := range shortest {
+			x, y := c[0], c[1]
+			if symmetry&1 != 0 {
+				x = 2 - x
+			}
+			if symmetry&2 != 0 {
+				y = 2 - y
+			}
+			if symmetry&4 != 0 {
+				x, y = y, x
+			}
+			cycle[i] = index[problem.Point{X: 10 * x, Y: 10 * y}]
+		}
+		cycle = canonicalCycle(cycle, true)
+		if initial == nil || cycleLess(initial, cycle) {
+			initial = cycle
+		}
+	}
+
+	// cycles of the same length are not forwarded, only the final event carries the winner of the tie-break
+	a := &BruteForce{Parallel: true}
+	a.SetInitialCycle(initial)
+	u := make(chan Progress, 10)
+	go a.Solve(context.Background(), p.Adjacency, u)
+	last := math.MaxFloat64
+	for progress := range u {
+		if progress.Cycle == nil {
+			continue
+		}
+		if progress.Final {
+			if progress.Distance > last || !cycleLess(progress.Cycle, initial) {
+				t.Fatalf("final cycle %v did not win the tie-break against %v", progress.Cycle, initial)
+			}
+			continue
+		}
+		if progress.Distance >= last {
+			t.Fatalf("distances do not strictly decrease: %f after %f", progress.Distance, last)
+		}
+		last = progress.Distance
+	}
+}
+
+func TestBruteForceProgress(t *testing.T) {
+	p := randomProblem(14, 10)
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	u := make(chan Progress, 10)
+	go NewBruteForce().Solve(ctx, p.Adjacency, u)
+
+	var statistics, final Progress
+	for progress := range u {
+		if progress.Final {
+			final = progress
+		} else if progress.Cycle == nil {
+			statistics = progress
+		}
+	}
+
+	if statistics.Stats["calculations per second"] <= 0 || statistics.Iteration <= 0 {
+		t.Fatalf("expected calculations in the statistics: %+v", statistics)
+	}
+	if !final.Final || final.Optimal {
+		t.Fatalf("expected a final event without proof of optimality after cancelling: %+v", final)
+	}
+}
+
+func TestBruteForceInitialCycle(t *testing.T) {
+	p := randomProblem(9, 11)
+	optimal := NewHeldKarp()
+	solveProblem(t, optimal, p)
+
+	// starting from the shortest cycle, there is no shorter cycle to report
+	for _, a := range []*BruteForce{NewBruteForce(), {Parallel: true}} {
+		a.SetInitialCycle(optimal.shortestCycle)
+		u := make(chan Progress, 10)
+		go a.Solve(context.Background(), p.Adjacency, u)
+
+		cycles := 0
+		for progress := range u {
+			if progress.Cycle != nil {
+				cycles++
+			}
+			if progress.Final && math.Abs(progress.Distance-optimal.shortestDistance) > 1e-9 {
+				t.Fatalf("distance %f differs from optimal distance %f", progress.Distance, optimal.shortestDistance)
+			}
+		}
+		if cycles != 1 {
+			t.Fatalf("expected only the initial cycle, got %d cycles", cycles)
+		}
+	}
+}
+
+func TestBruteForceResume(t *testing.T) {
+	// the parallel search prunes, it needs more points to be cancelled before it finishes
+	for parallel, n := range map[bool]int{false: 11, true: 13} {
+		p := randomProblem(n, 12)
+		optimal := solveProblem(t, NewHeldKarp(), p)
+
+		// cancel the search early, the final event carries the state of the search
+		a := &BruteForce{Parallel: parallel}
+		a.SetCheckpointInterval(time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		events := checkpointEvents(t, ctx, a, p)
+		cancel()
+		state := events[len(events)-1].State
+		if state == nil {
+			t.Fatalf("%s: expected a state in the final event", a)
+		}
+
+		// the resumed search finishes with the shortest cycle
+		resumed := &BruteForce{Parallel: parallel}
+		if err := resumed.Restore(state); err != nil {
+			t.Fatal(err)
+		}
+		if d := solveProblem(t, resumed, p); math.Abs(d-optimal) > 1e-9 || !resumed.Optimal() {
+			t.Fatalf("%s: resumed search found %f, optimal distance is %f", a, d, optimal)
+		}
+	}
+}
+
+func TestBruteForceAsymmetric(t *testing.T) {
+	for _, n := range []int{3, 4, 7} {
+		if d := solveProblem(t, NewBruteForce(), oneWayRing(n)); d != float64(n) {
+			t.Fatalf("%d points: wrong distance on a one-way ring: %f", n, d)
+		}
+		if d := solveProblem(t, &BruteForce{Parallel: true}, oneWayRing(n)); d != float64(n) {
+			t.Fatalf("%d points: wrong distance of the parallel search on a one-way ring: %f", n, d)
+		}
+	}
+
+	// the incremental distance of the sequential search follows the direction of the cycle
+	sequential := solveProblem(t, NewBruteForce(), asymmetricProblem(9, 7))
+	parallel := solveProblem(t, &BruteForce{Parallel: true}, asymmetricProblem(9, 7))
+	if sequential != parallel {
+		t.Fatalf("distances differ: sequential=%f, parallel=%f", sequential, parallel)
+	}
+}
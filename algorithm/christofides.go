@@ -0,0 +1,229 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// default and maximum number of odd-degree points for which the perfect matching is calculated
+// exactly. the exact matching needs 9 bytes for each of the 2^k subsets of the k odd points
+const christofidesExactMatching = 22
+
+// extends a minimum spanning tree by a minimum-weight perfect matching on the points with odd
+// degree and shortcuts an eulerian circuit of the result. the matching is exact for up to
+// ExactMatching odd points, then by the triangle inequality the resulting cycle is at most 1.5
+// times as long as the shortest one. with more odd points, the matching is approximated greedily
+// and the bound does not hold
+type Christofides struct {
+	ExactMatching    int
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewChristofides() *Christofides {
+	return &Christofides{ExactMatching: christofidesExactMatching}
+}
+
+// sets the parameters of christofides, supported keys are:
+//   - exact: the maximum number of odd-degree points whose matching is calculated exactly
+func (a *Christofides) Configure(key, value string) error {
+	switch key {
+	case "exact":
+		exact, err := strconv.Atoi(value)
+		if err != nil || exact < 0 || exact > christofidesExactMatching {
+			return fmt.Errorf("invalid number of points for the exact matching: %s, expected at most %d",
+				value, christofidesExactMatching)
+		}
+		a.ExactMatching = exact
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *Christofides) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using christofides", n)
+
+	if n > 0 {
+		tree := minimumSpanningTree(adjacency)
+
+		// points with odd degree in the tree, there is always an even number of them
+		degrees := make([]int, n)
+		for _, e := range tree {
+			degrees[e.i]++
+			degrees[e.j]++
+		}
+		odd := make([]int, 0)
+		for p, d := range degrees {
+			if d%2 == 1 {
+				odd = append(odd, p)
+			}
+		}
+
+		var matching []edge
+		if len(odd) <= a.ExactMatching {
+			matching = minimumPerfectMatching(adjacency, odd)
+		} else {
+			log.Printf("%d points with odd degree exceed the limit of %d for the exact matching, approximating "+
+				"the matching, the cycle may be more than 1.5 times as long as the shortest one", len(odd), a.ExactMatching)
+			matching = greedyPerfectMatching(adjacency, odd)
+		}
+
+		circuit := eulerianCircuit(n, append(tree, matching...))
+		a.shortestCycle = shortcut(n, circuit)
+		a.shortestDistance = cycleDistance(adjacency, a.shortestCycle)
+		updates <- Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Final: true}
+	}
+
+	return nil
+}
+
+func (a Christofides) String() string {
+	return "Christofides"
+}
+
+// calculates a minimum-weight perfect matching of the points by dynamic programming over all
+// subsets of points. the lowest point of a subset is matched to one of the others, the rest of
+// the subset has been matched before
+func minimumPerfectMatching(adjacency problem.Adjacency, points []int) []edge {
+	k := uint(len(points))
+	subsets := uint(1) << k
+	table := make([]float64, subsets)
+	partners := make([]uint8, subsets)
+
+	for mask := uint(1); mask < subsets; mask++ {
+		table[mask] = math.MaxFloat64
+
+		// subsets with an odd number of points can't be matched
+		if bits.OnesCount(mask)%2 == 1 {
+			continue
+		}
+
+		i := uint(bits.TrailingZeros(mask))
+		for j := i + 1; j < k; j++ {
+			if mask&(1<<j) == 0 {
+				continue
+			}
+			rest := mask &^ (1 << i) &^ (1 << j)
+			cost := adjacency[points[i]][points[j]] + table[rest]
+			if cost < table[mask] {
+				table[mask] = cost
+				partners[mask] = uint8(j)
+			}
+		}
+	}
+
+	matching := make([]edge, 0, k/2)
+	for mask := subsets - 1; mask != 0; {
+		i := uint(bits.TrailingZeros(mask))
+		j := uint(partners[mask])
+		matching = append(matching, edge{i: points[i], j: points[j], dist: adjacency[points[i]][points[j]]})
+		mask &^= 1<<i | 1<<j
+	}
+
+	return matching
+}
+
+// calculates a perfect matching of the points by greedily matching the closest pairs, followed by
+// exchanging the partners of two pairs as long as that shortens the matching
+func greedyPerfectMatching(adjacency problem.Adjacency, points []int) []edge {
+	candidates := make([]edge, 0, len(points)*len(points)/2)
+	for x := range points {
+		for y := x + 1; y < len(points); y++ {
+			candidates = append(candidates, edge{i: points[x], j: points[y], dist: adjacency[points[x]][points[y]]})
+		}
+	}
+	sort.Slice(candidates, func(x, y int) bool { return candidates[x].dist < candidates[y].dist })
+
+	matched := make(map[int]bool, len(points))
+	matching := make([]edge, 0, len(points)/2)
+	for _, e := range candidates {
+		if matched[e.i] || matched[e.j] {
+			continue
+		}
+		matched[e.i], matched[e.j] = true, true
+		matching = append(matching, e)
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for x := range matching {
+			for y := x + 1; y < len(matching); y++ {
+				a, b, c, d := matching[x].i, matching[x].j, matching[y].i, matching[y].j
+				current := adjacency[a][b] + adjacency[c][d]
+				if adjacency[a][c]+adjacency[b][d] < current-epsilon {
+					matching[x] = edge{i: a, j: c, dist: adjacency[a][c]}
+					matching[y] = edge{i: b, j: d, dist: adjacency[b][d]}
+					improved = true
+				} else if adjacency[a][d]+adjacency[b][c] < current-epsilon {
+					matching[x] = edge{i: a, j: d, dist: adjacency[a][d]}
+					matching[y] = edge{i: b, j: c, dist: adjacency[b][c]}
+					improved = true
+				}
+			}
+		}
+	}
+
+	return matching
+}
+
+// finds a circuit that uses every edge of a connected multigraph with even degrees exactly once,
+// using hierholzer's algorithm. returns the points in the order they are visited
+func eulerianCircuit(n int, edges []edge) []int {
+	incident := make([][]int, n)
+	for k, e := range edges {
+		incident[e.i] = append(incident[e.i], k)
+		incident[e.j] = append(incident[e.j], k)
+	}
+
+	used := make([]bool, len(edges))
+	next := make([]int, n)
+	circuit := make([]int, 0, len(edges)+1)
+	stack := []int{0}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+
+		// skip edges that were already used from the other side
+		for next[current] < len(incident[current]) && used[incident[current][next[current]]] {
+			next[current]++
+		}
+
+		if next[current] == len(incident[current]) {
+			circuit = append(circuit, current)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		k := incident[current][next[current]]
+		used[k] = true
+		if edges[k].i == current {
+			stack = append(stack, edges[k].j)
+		} else {
+			stack = append(stack, edges[k].i)
+		}
+	}
+
+	return circuit
+}
+
+// turns a circuit into a cycle by skipping points that were already visited
+func shortcut(n int, circuit []int) problem.Cycle {
+	visited := make([]bool, n)
+	cycle := make(problem.Cycle, 0, n)
+	for _, p := range circuit {
+		if !visited[p] {
+			visited[p] = true
+			cycle = append(cycle, p)
+		}
+	}
+	return cycle
+}
@@ -0,0 +1,60 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestChristofides(t *testing.T) {
+	testApproximation(t, func() Algorithm { return NewChristofides() }, 1.5)
+}
+
+func TestChristofidesConfigure(t *testing.T) {
+	a, err := FromString("christofides:exact=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.(*Christofides).ExactMatching != 0 {
+		t.Fatalf("parameters not applied: %+v", a)
+	}
+
+	// without an exact matching, the cycle is still valid but not bounded by 1.5
+	p := randomProblem(50, 1)
+	if d := solveProblem(t, a, p); d <= 0 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+
+	for _, spec := range []string{"christofides:exact=-1", "christofides:exact=23"} {
+		if _, err := FromString(spec); err == nil {
+			t.Fatalf("%s: expected error for invalid limit", spec)
+		}
+	}
+}
+
+func TestPerfectMatching(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 10, Y: 0}, {X: 11, Y: 0},
+		{X: 0, Y: 10}, {X: 0, Y: 12}, {X: 10, Y: 10}, {X: 13, Y: 10},
+	}
+	p := problem.NewProblem(points)
+	all := identityCycle(len(points))
+
+	exact := minimumPerfectMatching(p.Adjacency, all)
+	greedy := greedyPerfectMatching(p.Adjacency, all)
+
+	weight := func(matching []edge) float64 {
+		var w float64
+		for _, e := range matching {
+			w += e.dist
+		}
+		return w
+	}
+
+	if len(exact) != 4 || math.Round(weight(exact)) != 7 {
+		t.Fatalf("wrong exact matching: %v", exact)
+	}
+	if len(greedy) != 4 || weight(greedy) < weight(exact) {
+		t.Fatalf("wrong greedy matching: %v", greedy)
+	}
+}
@@ -0,0 +1,408 @@
+package algorithm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// a construction heuristic, builds a cycle from scratch. points are only needed by heuristics that
+// work on the coordinates instead of the distances, e.g. the convex hull
+type ConstructionFunc func(adjacency problem.Adjacency, points []problem.Point) problem.Cycle
+
+// builds a cycle with a construction heuristic and sends it as the only update
+type Construction struct {
+	name             string
+	construct        ConstructionFunc
+	geometric        bool
+	asymmetric       bool
+	points           []problem.Point
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewNearestNeighbour() *Construction {
+	return &Construction{
+		name:       "Nearest Neighbour",
+		asymmetric: true,
+		construct: func(adjacency problem.Adjacency, _ []problem.Point) problem.Cycle {
+			return NearestNeighbour(adjacency, 0)
+		},
+	}
+}
+
+func NewGreedyEdge() *Construction {
+	return &Construction{name: "Greedy Edge", construct: ignorePoints(GreedyEdge)}
+}
+
+func NewNearestInsertion() *Construction {
+	return &Construction{name: "Nearest Insertion", construct: ignorePoints(NearestInsertion), asymmetric: true}
+}
+
+func NewCheapestInsertion() *Construction {
+	return &Construction{name: "Cheapest Insertion", construct: ignorePoints(CheapestInsertion), asymmetric: true}
+}
+
+func NewFarthestInsertion() *Construction {
+	return &Construction{name: "Farthest Insertion", construct: ignorePoints(FarthestInsertion), asymmetric: true}
+}
+
+func NewConvexHullInsertion() *Construction {
+	return &Construction{name: "Convex Hull Insertion", construct: ConvexHullInsertion, geometric: true}
+}
+
+// sets the coordinates of the points, needed by geometric heuristics
+func (a *Construction) SetPoints(points []problem.Point) {
+	a.points = points
+}
+
+// tells if the heuristic follows the direction of the distances, e.g. nearest neighbour always goes
+// to the nearest point from the current one, while greedy edge and the convex hull ignore it
+func (a *Construction) SupportsAsymmetric() bool {
+	return a.asymmetric
+}
+
+// tests if the coordinates of the points are known, in case the heuristic needs them
+func (a *Construction) Validate(adjacency problem.Adjacency) error {
+	if a.geometric && len(a.points) != len(adjacency) {
+		return errors.New(a.name + " needs the coordinates of the points")
+	}
+	return nil
+}
+
+func (a *Construction) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	log.Printf("solving problemset with %d entries using %s", len(adjacency), a.name)
+
+	if err := a.Validate(adjacency); err != nil {
+		return err
+	}
+
+	if len(adjacency) > 0 {
+		a.shortestCycle = a.construct(adjacency, a.points)
+		a.shortestDistance = cycleDistance(adjacency, a.shortestCycle)
+		updates <- Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Final: true}
+	}
+
+	return nil
+}
+
+func (a Construction) String() string {
+	return a.name
+}
+
+func ignorePoints(construct func(problem.Adjacency) problem.Cycle) ConstructionFunc {
+	return func(adjacency problem.Adjacency, _ []problem.Point) problem.Cycle {
+		return construct(adjacency)
+	}
+}
+
+// returns the cycle that visits the points in ascending order
+func identityCycle(n int) problem.Cycle {
+	cycle := make(problem.Cycle, n)
+	for i := range cycle {
+		cycle[i] = i
+	}
+	return cycle
+}
+
+// builds a cycle by starting at point start and always going to the nearest point not visited yet
+func NearestNeighbour(adjacency problem.Adjacency, start int) problem.Cycle {
+	n := len(adjacency)
+	cycle := make(problem.Cycle, 0, n)
+	if n == 0 {
+		return cycle
+	}
+
+	visited := make([]bool, n)
+	current := start
+	for {
+		visited[current] = true
+		cycle = append(cycle, current)
+
+		next := -1
+		for j := range adjacency {
+			if !visited[j] && (next == -1 || adjacency[current][j] < adjacency[current][next]) {
+				next = j
+			}
+		}
+
+		if next == -1 {
+			return cycle
+		}
+		current = next
+	}
+}
+
+// builds a cycle by adding the shortest edges first, as long as no point gets more than two edges
+// and no edge closes a cycle before all points are connected
+func GreedyEdge(adjacency problem.Adjacency) problem.Cycle {
+	n := len(adjacency)
+	if n < 3 {
+		return identityCycle(n)
+	}
+
+	edges := make([]edge, 0, n*(n-1)/2)
+	for i := range adjacency {
+		for j := i + 1; j < n; j++ {
+			edges = append(edges, edge{i: i, j: j, dist: adjacency[i][j]})
+		}
+	}
+	sort.Slice(edges, func(x, y int) bool { return edges[x].dist < edges[y].dist })
+
+	// union-find to detect cycles, the neighbours of every point on the path
+	components := make([]int, n)
+	for i := range components {
+		components[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if components[i] != i {
+			components[i] = find(components[i])
+		}
+		return components[i]
+	}
+	neighbours := make([][]int, n)
+
+	added := 0
+	for _, e := range edges {
+		if added == n-1 {
+			break
+		}
+		if len(neighbours[e.i]) == 2 || len(neighbours[e.j]) == 2 || find(e.i) == find(e.j) {
+			continue
+		}
+		components[find(e.i)] = find(e.j)
+		neighbours[e.i] = append(neighbours[e.i], e.j)
+		neighbours[e.j] = append(neighbours[e.j], e.i)
+		added++
+	}
+
+	// the edges form a path, walk it from one of its ends
+	start := 0
+	for i := range neighbours {
+		if len(neighbours[i]) == 1 {
+			start = i
+			break
+		}
+	}
+	cycle := make(problem.Cycle, 0, n)
+	previous, current := -1, start
+	for len(cycle) < n {
+		cycle = append(cycle, current)
+		for _, next := range neighbours[current] {
+			if next != previous {
+				previous, current = current, next
+				break
+			}
+		}
+	}
+
+	return cycle
+}
+
+// builds a cycle by repeatedly inserting the point that is nearest to the cycle
+func NearestInsertion(adjacency problem.Adjacency) problem.Cycle {
+	return distanceInsertion(adjacency, func(distance, best float64) bool { return distance < best })
+}
+
+// builds a cycle by repeatedly inserting the point that is farthest from the cycle
+func FarthestInsertion(adjacency problem.Adjacency) problem.Cycle {
+	return distanceInsertion(adjacency, func(distance, best float64) bool { return distance > best })
+}
+
+// builds a cycle by repeatedly inserting the point that increases the length of the cycle the least
+func CheapestInsertion(adjacency problem.Adjacency) problem.Cycle {
+	if len(adjacency) == 0 {
+		return problem.Cycle{}
+	}
+	return costInsertion(adjacency, []int{0}, func(cost, _ float64) float64 { return cost })
+}
+
+// builds a cycle by starting with the convex hull of the points and repeatedly inserting the point
+// whose insertion increases the length of the replaced edge by the smallest ratio
+func ConvexHullInsertion(adjacency problem.Adjacency, points []problem.Point) problem.Cycle {
+	if len(adjacency) == 0 {
+		return problem.Cycle{}
+	}
+	return costInsertion(adjacency, convexHull(points), func(cost, replaced float64) float64 {
+		if replaced == 0 {
+			return math.MaxFloat64
+		}
+		return (cost + replaced) / replaced
+	})
+}
+
+// a cycle that points are inserted into, successors are stored per point
+type insertionCycle struct {
+	adjacency problem.Adjacency
+	next      []int
+	first     int
+}
+
+func newInsertionCycle(adjacency problem.Adjacency, initial []int) *insertionCycle {
+	c := &insertionCycle{adjacency: adjacency, next: make([]int, len(adjacency)), first: initial[0]}
+	for i := range c.next {
+		c.next[i] = -1
+	}
+	for k, p := range initial {
+		c.next[p] = initial[(k+1)%len(initial)]
+	}
+	return c
+}
+
+func (c *insertionCycle) contains(p int) bool {
+	return c.next[p] != -1
+}
+
+// increase of the length when p is inserted after i
+func (c *insertionCycle) cost(i, p int) float64 {
+	j := c.next[i]
+	return c.adjacency[i][p] + c.adjacency[p][j] - c.adjacency[i][j]
+}
+
+// returns the point after which the insertion of p is cheapest
+func (c *insertionCycle) cheapest(p int) int {
+	best := c.first
+	for i := c.next[c.first]; i != c.first; i = c.next[i] {
+		if c.cost(i, p) < c.cost(best, p) {
+			best = i
+		}
+	}
+	return best
+}
+
+func (c *insertionCycle) insert(i, p int) {
+	c.next[p] = c.next[i]
+	c.next[i] = p
+}
+
+func (c *insertionCycle) Cycle() problem.Cycle {
+	cycle := problem.Cycle{c.first}
+	for i := c.next[c.first]; i != c.first; i = c.next[i] {
+		cycle = append(cycle, i)
+	}
+	return cycle
+}
+
+// inserts the points in the order determined by their distance to the cycle, better decides if
+// a distance is preferred over the best one so far. each point is inserted where it is cheapest
+func distanceInsertion(adjacency problem.Adjacency, better func(distance, best float64) bool) problem.Cycle {
+	n := len(adjacency)
+	if n == 0 {
+		return problem.Cycle{}
+	}
+
+	c := newInsertionCycle(adjacency, []int{0})
+	distances := make([]float64, n)
+	copy(distances, adjacency[0])
+
+	for size := 1; size < n; size++ {
+		p := -1
+		for k := range distances {
+			if !c.contains(k) && (p == -1 || better(distances[k], distances[p])) {
+				p = k
+			}
+		}
+
+		c.insert(c.cheapest(p), p)
+		for k := range distances {
+			distances[k] = math.Min(distances[k], adjacency[p][k])
+		}
+	}
+
+	return c.Cycle()
+}
+
+// inserts the points, starting from the initial cycle, by choosing the point whose cheapest
+// insertion has the lowest score. score is called with the increase of the length and the length
+// of the edge that is replaced
+func costInsertion(adjacency problem.Adjacency, initial []int, score func(cost, replaced float64) float64) problem.Cycle {
+	n := len(adjacency)
+	c := newInsertionCycle(adjacency, initial)
+
+	// the point after which the insertion of every point is cheapest
+	after := make([]int, n)
+	for k := range after {
+		if !c.contains(k) {
+			after[k] = c.cheapest(k)
+		}
+	}
+
+	for size := len(initial); size < n; size++ {
+		p := -1
+		var pScore float64
+		for k := range after {
+			if c.contains(k) {
+				continue
+			}
+			i := after[k]
+			if s := score(c.cost(i, k), adjacency[i][c.next[i]]); p == -1 || s < pScore {
+				p, pScore = k, s
+			}
+		}
+
+		i := after[p]
+		c.insert(i, p)
+
+		// the edge after i was replaced, points that were to be inserted there have to look again.
+		// for all others, only the two new edges, after i and after p, could be cheaper
+		for k := range after {
+			if c.contains(k) {
+				continue
+			}
+			if after[k] == i {
+				after[k] = c.cheapest(k)
+				continue
+			}
+			if c.cost(i, k) < c.cost(after[k], k) {
+				after[k] = i
+			}
+			if c.cost(p, k) < c.cost(after[k], k) {
+				after[k] = p
+			}
+		}
+	}
+
+	return c.Cycle()
+}
+
+// calculates the convex hull of the points using andrew's monotone chain, returns the indices of
+// the points on the hull in counter-clockwise order
+func convexHull(points []problem.Point) []int {
+	indices := identityCycle(len(points))
+	sort.Slice(indices, func(x, y int) bool {
+		a, b := points[indices[x]], points[indices[y]]
+		return a.X < b.X || (a.X == b.X && a.Y < b.Y)
+	})
+	if len(indices) < 3 {
+		return indices
+	}
+
+	cross := func(o, a, b int) float64 {
+		return (points[a].X-points[o].X)*(points[b].Y-points[o].Y) - (points[a].Y-points[o].Y)*(points[b].X-points[o].X)
+	}
+
+	hull := make([]int, 0, 2*len(indices))
+	for _, p := range indices {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for k := len(indices) - 2; k >= 0; k-- {
+		p := indices[k]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// the last point is the first one again
+	return hull[:len(hull)-1]
+}
@@ -0,0 +1,152 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var constructions = map[string]func() Algorithm{
+	"nn":                func() Algorithm { return NewNearestNeighbour() },
+	"greedy":            func() Algorithm { return NewGreedyEdge() },
+	"nearestinsertion":  func() Algorithm { return NewNearestInsertion() },
+	"cheapestinsertion": func() Algorithm { return NewCheapestInsertion() },
+	"farthestinsertion": func() Algorithm { return NewFarthestInsertion() },
+	"convexhull":        func() Algorithm { return NewConvexHullInsertion() },
+}
+
+// solves the problem with a construction heuristic, setting the points if needed
+func solveConstruction(t *testing.T, a Algorithm, p *problem.Problem) float64 {
+	if geometric, ok := a.(Geometric); ok {
+		geometric.SetPoints(p.Points)
+	}
+	return solveProblem(t, a, p)
+}
+
+func TestConstruction(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	// nearest neighbour only finds the shortest cycle from some of the points, so the order of the
+	// points must not depend on the time
+	for name, constructor := range constructions {
+		p := problem.NewProblemWithRandom(points, rand.New(rand.NewSource(1)))
+		if d := solveConstruction(t, constructor(), p); math.Round(d*100)/100 != 220.71 {
+			t.Fatalf("%s: wrong distance: %f", name, d)
+		}
+	}
+}
+
+func TestConstructionApproximation(t *testing.T) {
+	p := randomProblem(500, 3)
+	lowerBound, _ := heldKarpBound(context.Background(), p.Adjacency, cycleDistance(p.Adjacency, NearestNeighbour(p.Adjacency, 0)), 100)
+
+	// nearest neighbour and greedy edge are usually within 25%, the insertions within 40% of the optimum
+	for name, constructor := range constructions {
+		q := problem.NewProblem(p.Points)
+		if d := solveConstruction(t, constructor(), q); d > 1.5*lowerBound {
+			t.Fatalf("%s: distance %f exceeds 1.5 * lower bound %f", name, d, lowerBound)
+		}
+	}
+}
+
+func TestConstructionNeedsPoints(t *testing.T) {
+	p := randomProblem(10, 4)
+	if err := NewConvexHullInsertion().Validate(p.Adjacency); err == nil {
+		t.Fatal("convex hull insertion without points must be refused")
+	}
+	if err := NewGreedyEdge().Validate(p.Adjacency); err != nil {
+		t.Fatalf("greedy edge refused: %s", err)
+	}
+}
+
+func TestCostInsertionMatchesNaive(t *testing.T) {
+	// recalculates the cheapest insertion of every point in every step
+	naive := func(adjacency problem.Adjacency, initial []int, score func(cost, replaced float64) float64) problem.Cycle {
+		c := newInsertionCycle(adjacency, initial)
+		for size := len(initial); size < len(adjacency); size++ {
+			p, pAfter := -1, -1
+			var pScore float64
+			for k := range adjacency {
+				if c.contains(k) {
+					continue
+				}
+				i := c.cheapest(k)
+				if s := score(c.cost(i, k), adjacency[i][c.next[i]]); p == -1 || s < pScore {
+					p, pAfter, pScore = k, i, s
+				}
+			}
+			c.insert(pAfter, p)
+		}
+		return c.Cycle()
+	}
+	cheapest := func(cost, _ float64) float64 { return cost }
+	ratio := func(cost, replaced float64) float64 {
+		if replaced == 0 {
+			return math.MaxFloat64
+		}
+		return (cost + replaced) / replaced
+	}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		p := randomProblem(30, seed)
+		if d, expected := cycleDistance(p.Adjacency, CheapestInsertion(p.Adjacency)),
+			cycleDistance(p.Adjacency, naive(p.Adjacency, []int{0}, cheapest)); math.Abs(d-expected) > epsilon {
+			t.Fatalf("seed %d: cheapest insertion has distance %f, expected %f", seed, d, expected)
+		}
+		if d, expected := cycleDistance(p.Adjacency, ConvexHullInsertion(p.Adjacency, p.Points)),
+			cycleDistance(p.Adjacency, naive(p.Adjacency, convexHull(p.Points), ratio)); math.Abs(d-expected) > epsilon {
+			t.Fatalf("seed %d: convex hull insertion has distance %f, expected %f", seed, d, expected)
+		}
+	}
+}
+
+func TestConvexHull(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 25, Y: 25},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 10, Y: 40},
+		{X: 0, Y: 50},
+		{X: 25, Y: 0},
+	}
+
+	hull := convexHull(points)
+	expected := []int{0, 2, 3, 5}
+	if len(hull) != len(expected) {
+		t.Fatalf("wrong hull: %v", hull)
+	}
+	for k := range hull {
+		if hull[k] != expected[k] {
+			t.Fatalf("wrong hull: %v", hull)
+		}
+	}
+}
+
+func TestConstructionAsymmetric(t *testing.T) {
+	for name, constructor := range constructions {
+		a := constructor()
+		if !supportsAsymmetric(a) {
+			continue
+		}
+
+		// nearest neighbour follows the ring, the insertions at least report the directed distances
+		d := solveConstruction(t, a, asymmetricProblem(30, 9))
+		if name == "nn" {
+			if d := solveConstruction(t, a, oneWayRing(30)); d != 30 {
+				t.Fatalf("%s: wrong distance on a one-way ring: %f", name, d)
+			}
+		}
+		if d > 30*100 {
+			t.Fatalf("%s: distance %f exceeds the longest possible cycle", name, d)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package algorithm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// crossover operators of the genetic algorithm
+const (
+	// order crossover, copies a segment of the first parent and fills the rest in the order of the second
+	CrossoverOrder = "ox"
+
+	// edge recombination, builds the child from the union of the edges of both parents
+	CrossoverEdgeRecombination = "erx"
+)
+
+const (
+	DefaultPopulation   = 100
+	DefaultMutationRate = 0.1
+	DefaultGenerations  = 1000
+	DefaultTournament   = 3
+	DefaultElitism      = 2
+)
+
+// evolves a population of cycles. parents are chosen by tournament selection and combined by
+// crossover, children are mutated by reversing a random segment. the shortest cycles of every
+// generation survive unchanged (elitism)
+type Genetic struct {
+	warmStart
+	checkpoints
+	randomness
+	resume           *geneticState
+	Population       int
+	MutationRate     float64
+	Generations      int
+	Tournament       int
+	Elitism          int
+	Crossover        string
+	Statistics       chan<- GenerationStatistics
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+// statistics about a single generation of the genetic algorithm
+type GenerationStatistics struct {
+	Generation int     `json:"generation"`
+	Best       float64 `json:"best"`
+	Mean       float64 `json:"mean"`
+
+	// share of edges that differ between the individuals, 0 if all of them are equal
+	Diversity float64 `json:"diversity"`
+}
+
+// state of the genetic algorithm at the start of a generation
+type geneticState struct {
+	Generation int             `json:"generation"`
+	Population []problem.Cycle `json:"population"`
+}
+
+// a cycle and its distance
+type individual struct {
+	cycle    problem.Cycle
+	distance float64
+}
+
+func NewGenetic() *Genetic {
+	return &Genetic{
+		Population:       DefaultPopulation,
+		MutationRate:     DefaultMutationRate,
+		Generations:      DefaultGenerations,
+		Tournament:       DefaultTournament,
+		Elitism:          DefaultElitism,
+		Crossover:        CrossoverOrder,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of the genetic algorithm, supported keys are:
+//   - population: number of individuals in every generation
+//   - mutation: probability that a child is mutated
+//   - generations: number of generations after which the algorithm finishes, 0 for no limit
+//   - tournament: number of individuals that compete in a tournament selection
+//   - elitism: number of shortest individuals that survive unchanged
+//   - crossover: ox or erx
+func (a *Genetic) Configure(key, value string) error {
+	var err error
+	switch key {
+	case "population":
+		a.Population, err = strconv.Atoi(value)
+		if err != nil || a.Population < 2 {
+			return fmt.Errorf("invalid population size: %s", value)
+		}
+	case "mutation":
+		a.MutationRate, err = strconv.ParseFloat(value, 64)
+		if err != nil || a.MutationRate < 0 || a.MutationRate > 1 {
+			return fmt.Errorf("invalid mutation rate: %s", value)
+		}
+	case "generations":
+		a.Generations, err = strconv.Atoi(value)
+		if err != nil || a.Generations < 0 {
+			return fmt.Errorf("invalid number of generations: %s", value)
+		}
+	case "tournament":
+		a.Tournament, err = strconv.Atoi(value)
+		if err != nil || a.Tournament < 1 {
+			return fmt.Errorf("invalid tournament size: %s", value)
+		}
+	case "elitism":
+		a.Elitism, err = strconv.Atoi(value)
+		if err != nil || a.Elitism < 0 {
+			return fmt.Errorf("invalid number of elites: %s", value)
+		}
+	case "crossover":
+		crossover := strings.ToLower(value)
+		if crossover != CrossoverOrder && crossover != CrossoverEdgeRecombination {
+			return fmt.Errorf("unknown crossover: %s", value)
+		}
+		a.Crossover = crossover
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *Genetic) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using a genetic algorithm, population %d, %s crossover",
+		n, a.Population, a.Crossover)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+
+		a.startCheckpoints()
+		random := a.random()
+
+		// one individual from the initial cycle or nearest neighbour, the rest is random
+		population := make([]individual, a.Population)
+		for i := range population {
+			var cycle problem.Cycle
+			if i == 0 {
+				cycle = a.startCycle(adjacency)
+			} else {
+				cycle = problem.Cycle(random.Perm(n))
+			}
+			population[i] = individual{cycle: cycle, distance: cycleDistance(adjacency, cycle)}
+		}
+
+		// or the population of a checkpoint
+		generation := 0
+		if state := a.resumeState(n); state != nil {
+			population = make([]individual, len(state.Population))
+			for i, cycle := range state.Population {
+				population[i] = individual{cycle: cycle, distance: cycleDistance(adjacency, cycle)}
+			}
+			generation = state.Generation
+			log.Printf("resuming the genetic algorithm at generation %d", generation)
+		}
+
+		elitism := a.Elitism
+		if elitism > len(population) {
+			elitism = len(population)
+		}
+
+		for !cancelled(ctx) {
+			if a.checkpointDue() {
+				throttled.Checkpoint(Progress{Iteration: generation, State: a.state(generation, population)})
+			}
+
+			generation++
+			sort.Slice(population, func(i, j int) bool { return population[i].distance < population[j].distance })
+
+			if population[0].distance < a.shortestDistance-epsilon {
+				a.shortestDistance = population[0].distance
+				a.shortestCycle = population[0].cycle
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Iteration: generation})
+			}
+			// statistics of every generation are sent to the statistics channel if there is one, the
+			// updates only get them once in a while
+			if a.Statistics != nil || throttled.StatisticsDue() {
+				statistics := generationStatistics(generation, population)
+				if a.Statistics != nil {
+					select {
+					case a.Statistics <- statistics:
+					case <-ctx.Done():
+					}
+				}
+				throttled.Report(Progress{Iteration: generation, Stats: statistics.stats()})
+			}
+
+			if a.Generations != 0 && generation >= a.Generations {
+				break
+			}
+
+			next := make([]individual, len(population))
+			copy(next, population[:elitism])
+			for i := elitism; i < len(next); i++ {
+				first, second := a.selectParent(population, random), a.selectParent(population, random)
+
+				var child problem.Cycle
+				if a.Crossover == CrossoverEdgeRecombination {
+					child = edgeRecombination(first.cycle, second.cycle, random)
+				} else {
+					child = orderCrossover(first.cycle, second.cycle, random)
+				}
+
+				if random.Float64() < a.MutationRate {
+					invert(child, random)
+				}
+
+				next[i] = individual{cycle: child, distance: cycleDistance(adjacency, child)}
+			}
+			population = next
+		}
+
+		progress := Progress{Iteration: generation, Stats: generationStatistics(generation, population).stats()}
+		if a.finalCheckpoint(ctx) {
+			progress.State = a.state(generation, population)
+		}
+		throttled.Finish(progress)
+	}
+
+	return ctx.Err()
+}
+
+// continues with the population of a checkpoint in the next search
+func (a *Genetic) Restore(state json.RawMessage) error {
+	var s geneticState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return fmt.Errorf("invalid state of %s: %s", a, err)
+	}
+	a.resume = &s
+	return nil
+}
+
+// returns the state to resume from and forgets it, nil if there is none or it does not fit the problem
+func (a *Genetic) resumeState(n int) *geneticState {
+	state := a.resume
+	a.resume = nil
+	if state == nil {
+		return nil
+	}
+
+	for _, cycle := range state.Population {
+		if !isPermutation(cycle, n) {
+			log.Printf("state of %s does not fit the problem, starting from scratch", a)
+			return nil
+		}
+	}
+	if len(state.Population) == 0 {
+		return nil
+	}
+	return state
+}
+
+// serializes the population at the start of a generation
+func (a *Genetic) state(generation int, population []individual) json.RawMessage {
+	cycles := make([]problem.Cycle, len(population))
+	for i, ind := range population {
+		cycles[i] = ind.cycle
+	}
+	return marshalState(geneticState{Generation: generation, Population: cycles})
+}
+
+func (a Genetic) String() string {
+	return "Genetic Algorithm"
+}
+
+// picks the shortest out of a.Tournament random individuals
+func (a *Genetic) selectParent(population []individual, random *rand.Rand) individual {
+	winner := population[random.Intn(len(population))]
+	for k := 1; k < a.Tournament; k++ {
+		contender := population[random.Intn(len(population))]
+		if contender.distance < winner.distance {
+			winner = contender
+		}
+	}
+	return winner
+}
+
+// copies a random segment of the first parent to the child, the remaining points are filled in
+// the order they appear in the second parent, starting after the segment
+func orderCrossover(first, second problem.Cycle, random *rand.Rand) problem.Cycle {
+	n := len(first)
+	child := make(problem.Cycle, n)
+	if n < 2 {
+		copy(child, first)
+		return child
+	}
+
+	i, j := random.Intn(n), random.Intn(n)
+	if i > j {
+		i, j = j, i
+	}
+
+	inChild := make([]bool, n)
+	for k := i; k <= j; k++ {
+		child[k] = first[k]
+		inChild[first[k]] = true
+	}
+
+	position := (j + 1) % n
+	for k := 0; k < n; k++ {
+		p := second[(j+1+k)%n]
+		if inChild[p] {
+			continue
+		}
+		child[position] = p
+		position = (position + 1) % n
+	}
+
+	return child
+}
+
+// builds a child from the edges of both parents. starting at the first point of the first parent,
+// the next point is the neighbour, in either parent, that has the fewest unvisited neighbours left.
+// if there is none, a random unvisited point is chosen
+func edgeRecombination(first, second problem.Cycle, random *rand.Rand) problem.Cycle {
+	n := len(first)
+	neighbours := make([][]int, n)
+	addEdges := func(cycle problem.Cycle) {
+		for i, p := range cycle {
+			for _, q := range []int{cycle[(i+1)%n], cycle[(i-1+n)%n]} {
+				known := false
+				for _, r := range neighbours[p] {
+					known = known || r == q
+				}
+				if !known && p != q {
+					neighbours[p] = append(neighbours[p], q)
+				}
+			}
+		}
+	}
+	addEdges(first)
+	addEdges(second)
+
+	visited := make([]bool, n)
+	unvisited := func(p int) int {
+		count := 0
+		for _, q := range neighbours[p] {
+			if !visited[q] {
+				count++
+			}
+		}
+		return count
+	}
+
+	child := make(problem.Cycle, 0, n)
+	current := first[0]
+	for len(child) < n {
+		visited[current] = true
+		child = append(child, current)
+
+		next := -1
+		for _, q := range neighbours[current] {
+			if !visited[q] && (next == -1 || unvisited(q) < unvisited(next)) {
+				next = q
+			}
+		}
+
+		if next == -1 && len(child) < n {
+			remaining := make([]int, 0, n-len(child))
+			for p := range visited {
+				if !visited[p] {
+					remaining = append(remaining, p)
+				}
+			}
+			next = remaining[random.Intn(len(remaining))]
+		}
+		current = next
+	}
+
+	return child
+}
+
+// reverses a random segment of the cycle
+func invert(cycle problem.Cycle, random *rand.Rand) {
+	n := len(cycle)
+	if n < 2 {
+		return
+	}
+
+	i, j := random.Intn(n), random.Intn(n)
+	if i > j {
+		i, j = j, i
+	}
+	for ; i < j; i, j = i+1, j-1 {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	}
+}
+
+// calculates the statistics of a population
+func generationStatistics(generation int, population []individual) GenerationStatistics {
+	var sum float64
+	best := math.MaxFloat64
+	edges := make(map[[2]int]bool)
+	for _, ind := range population {
+		sum += ind.distance
+		best = math.Min(best, ind.distance)
+		for i, p := range ind.cycle {
+			q := ind.cycle[(i+1)%len(ind.cycle)]
+			if p > q {
+				p, q = q, p
+			}
+			edges[[2]int{p, q}] = true
+		}
+	}
+
+	// distinct edges, normalized between identical individuals and individuals without common edges
+	var diversity float64
+	n := len(population[0].cycle)
+	if total := n * len(population); total > n {
+		diversity = float64(len(edges)-n) / float64(total-n)
+	}
+
+	return GenerationStatistics{
+		Generation: generation,
+		Best:       best,
+		Mean:       sum / float64(len(population)),
+		Diversity:  diversity,
+	}
+}
+
+// the statistics as they are reported in progress events
+func (s GenerationStatistics) stats() map[string]float64 {
+	return map[string]float64{
+		"mean distance": s.Mean,
+		"diversity %":   math.Round(1000*s.Diversity) / 10,
+	}
+}
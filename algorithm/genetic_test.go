@@ -0,0 +1,147 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestGenetic(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	a := NewGenetic()
+	a.Generations = 50
+	if d := solveProblem(t, a, p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestGeneticCrossovers(t *testing.T) {
+	p := randomProblem(14, 6)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	for _, crossover := range []string{CrossoverOrder, CrossoverEdgeRecombination} {
+		a := NewGenetic()
+		a.Crossover = crossover
+		a.Generations = 300
+		if d := solveProblem(t, a, p); d > 1.05*optimal {
+			t.Fatalf("%s: distance %f is not within 5%% of optimal distance %f", crossover, d, optimal)
+		}
+	}
+}
+
+func TestGeneticStatistics(t *testing.T) {
+	p := randomProblem(20, 7)
+	a := NewGenetic()
+	a.Generations = 10
+	u := make(chan Progress, 10)
+	go a.Solve(context.Background(), p.Adjacency, u)
+
+	// the statistics of the last generation are part of the final event
+	var last Progress
+	for progress := range u {
+		last = progress
+	}
+	if last.Iteration != 10 {
+		t.Fatalf("expected 10 generations, got %d", last.Iteration)
+	}
+	mean, diversity := last.Stats["mean distance"], last.Stats["diversity %"]
+	if mean < a.shortestDistance || diversity < 0 || diversity > 100 {
+		t.Fatalf("invalid statistics: %v", last.Stats)
+	}
+
+	// with a statistics channel, every generation is sent exactly once
+	statistics := make(chan GenerationStatistics)
+	a = NewGenetic()
+	a.Generations = 10
+	a.Statistics = statistics
+	received := make(chan []GenerationStatistics)
+	go func() {
+		var all []GenerationStatistics
+		for s := range statistics {
+			all = append(all, s)
+		}
+		received <- all
+	}()
+	solveProblem(t, a, p)
+	close(statistics)
+
+	generation := 0
+	for _, s := range <-received {
+		if s.Generation != generation+1 {
+			t.Fatalf("expected generation %d, got %d", generation+1, s.Generation)
+		}
+		if s.Best > s.Mean || s.Diversity < 0 || s.Diversity > 1 {
+			t.Fatalf("invalid statistics: %+v", s)
+		}
+		generation = s.Generation
+	}
+	if generation != 10 {
+		t.Fatalf("expected statistics of 10 generations, got %d", generation)
+	}
+}
+
+func TestCrossoverPermutation(t *testing.T) {
+	first := problem.Cycle{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	second := problem.Cycle{9, 3, 7, 1, 5, 0, 8, 2, 6, 4}
+	random := rand.New(rand.NewSource(1))
+
+	for k := 0; k < 100; k++ {
+		for _, child := range []problem.Cycle{orderCrossover(first, second, random), edgeRecombination(first, second, random)} {
+			visited := make([]bool, len(child))
+			for _, p := range child {
+				if visited[p] {
+					t.Fatalf("child is not a permutation: %v", child)
+				}
+				visited[p] = true
+			}
+		}
+	}
+}
+
+func TestGeneticResume(t *testing.T) {
+	p := randomProblem(12, 13)
+
+	// a checkpoint at the start of every generation
+	a := NewGenetic()
+	a.Generations = 30
+	a.SetCheckpointInterval(time.Nanosecond)
+	events := checkpointEvents(t, context.Background(), a, p)
+	state := events[15].State
+	if events[15].Iteration != 15 {
+		t.Fatalf("expected a checkpoint in generation 15, got %d", events[15].Iteration)
+	}
+
+	// the resumed algorithm continues with the generation of the checkpoint
+	resumed := NewGenetic()
+	resumed.Generations = 30
+	resumed.SetCheckpointInterval(time.Nanosecond)
+	if err := resumed.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	if events := checkpointEvents(t, context.Background(), resumed, p); events[0].Iteration != 15 {
+		t.Fatalf("expected to resume in generation 15, got %d", events[0].Iteration)
+	}
+
+	// a state that does not fit the problem is ignored
+	if err := resumed.Restore(state); err != nil {
+		t.Fatal(err)
+	}
+	if events := checkpointEvents(t, context.Background(), resumed, randomProblem(10, 13)); events[0].Iteration != 0 {
+		t.Fatalf("expected to start from scratch, got generation %d", events[0].Iteration)
+	}
+
+	if err := resumed.Restore([]byte("[1, 2]")); err == nil {
+		t.Fatal("expected error for invalid state")
+	}
+}
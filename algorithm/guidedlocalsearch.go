@@ -0,0 +1,146 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// default weight of the penalties of guided local search, relative to the average edge of the
+// first local optimum
+const DefaultGuidedAlpha = 0.3
+
+// improves a cycle with a local search on distances that are augmented by penalties. whenever the
+// search is stuck in a local optimum, the edges of the cycle with the highest utility, their
+// distance divided by one plus their penalty, are penalized and the search continues. long edges
+// are penalized first and edges that were penalized often are spared, which leads the search away
+// from the local optimum. runs until the algorithm is stopped or the configured number of
+// iterations is reached
+type GuidedLocalSearch struct {
+	warmStart
+	Neighbours       int
+	Alpha            float64
+	Iterations       int
+	search           localOptimizer
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewGuidedLocalSearch() *GuidedLocalSearch {
+	return &GuidedLocalSearch{
+		Neighbours:       DefaultNeighbours,
+		Alpha:            DefaultGuidedAlpha,
+		search:           NewTwoOpt(),
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of guided local search, supported keys are:
+//   - search: the local search that is wrapped, 2opt, localsearch or linkernighan
+//   - neighbours: number of nearest neighbours considered for a move
+//   - alpha: weight of the penalties
+//   - iterations: number of penalty updates after which the algorithm finishes, 0 for no limit
+func (a *GuidedLocalSearch) Configure(key, value string) error {
+	switch key {
+	case "search":
+		search, err := localOptimizerFromString(value)
+		if err != nil {
+			return err
+		}
+		a.search = search
+	case "neighbours":
+		neighbours, err := strconv.Atoi(value)
+		if err != nil || neighbours < 1 {
+			return fmt.Errorf("invalid number of neighbours: %s", value)
+		}
+		a.Neighbours = neighbours
+	case "alpha":
+		alpha, err := strconv.ParseFloat(value, 64)
+		if err != nil || alpha <= 0 {
+			return fmt.Errorf("invalid alpha: %s", value)
+		}
+		a.Alpha = alpha
+	case "iterations":
+		iterations, err := strconv.Atoi(value)
+		if err != nil || iterations < 0 {
+			return fmt.Errorf("invalid number of iterations: %s", value)
+		}
+		a.Iterations = iterations
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *GuidedLocalSearch) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using %s, alpha %.2f", n, a, a.Alpha)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		neighbours := nearestNeighbours(adjacency, a.Neighbours)
+		t := newTour(a.startCycle(adjacency))
+		a.search.optimizeTour(ctx, adjacency, t, neighbours, t.Cycle())
+
+		a.shortestCycle = t.Cycle()
+		a.shortestDistance = t.distance(adjacency)
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance})
+
+		// the local search works on the augmented distances, which include the penalties
+		lambda := a.Alpha * a.shortestDistance / float64(n)
+		penalties := make([]int, n*n)
+		augmented := make(problem.Adjacency, n)
+		for i := range augmented {
+			augmented[i] = make([]float64, n)
+			copy(augmented[i], adjacency[i])
+		}
+
+		points := make([]int, 0, n)
+		penalized := 0
+		iteration := 1
+		for ; n >= 5 && !cancelled(ctx) && (a.Iterations == 0 || iteration <= a.Iterations); iteration++ {
+			maxUtility := 0.0
+			for _, p := range t.cycle {
+				q := t.next(p)
+				maxUtility = math.Max(maxUtility, adjacency[p][q]/float64(1+penalties[p*n+q]))
+			}
+
+			// penalize the edges with the highest utility and restart the search at their endpoints
+			points = points[:0]
+			for _, p := range t.cycle {
+				q := t.next(p)
+				if adjacency[p][q]/float64(1+penalties[p*n+q]) < maxUtility-epsilon {
+					continue
+				}
+				penalties[p*n+q]++
+				penalties[q*n+p]++
+				penalized++
+				augmented[p][q] = adjacency[p][q] + lambda*float64(penalties[p*n+q])
+				augmented[q][p] = augmented[p][q]
+				points = append(points, p, q)
+			}
+
+			a.search.optimizeTour(ctx, augmented, t, neighbours, points)
+
+			if distance := t.distance(adjacency); distance < a.shortestDistance-epsilon {
+				a.shortestDistance = distance
+				a.shortestCycle = t.Cycle()
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance, Iteration: iteration})
+			}
+			throttled.Report(Progress{Iteration: iteration, Stats: map[string]float64{"penalties": float64(penalized)}})
+		}
+
+		throttled.Finish(Progress{Iteration: iteration - 1, Stats: map[string]float64{"penalties": float64(penalized)}})
+	}
+
+	return ctx.Err()
+}
+
+func (a GuidedLocalSearch) String() string {
+	return fmt.Sprintf("Guided Local Search (%s)", a.search)
+}
@@ -0,0 +1,39 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestGuidedLocalSearch(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	a := NewGuidedLocalSearch()
+	a.Iterations = 100
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, a, p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestGuidedLocalSearchImproves(t *testing.T) {
+	p := randomProblem(300, 10)
+	twoOpt := solveProblem(t, NewTwoOpt(), p)
+
+	for _, search := range []string{"2opt", "localsearch", "linkernighan"} {
+		a, err := FromString("gls:iterations=3000:search=" + search)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := solveProblem(t, a, p); d >= twoOpt {
+			t.Fatalf("%s: distance %f is not shorter than 2-opt %f", a, d, twoOpt)
+		}
+	}
+}
@@ -1,159 +1,195 @@
 package algorithm
 
 import (
-	"leistungsnachweis-graphiker/problem"
+	"context"
+	"fmt"
+	"log"
 	"math"
+	"math/bits"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
 )
 
+// default amount of memory the tables of held-karp may occupy, in bytes
+const DefaultHeldKarpMemoryBudget = 4 << 30
+
+// size of a single table-entry in bytes, e.g. the distance (float64) and the predecessor (uint8)
+const heldKarpEntrySize = 8 + 1
+
 type HeldKarp struct {
-	running          bool
-	primes           []int
+	optimal          bool
+	MemoryBudget     uint64
 	shortestDistance float64
 	shortestCycle    problem.Cycle
 }
 
 func NewHeldKarp() *HeldKarp {
 	return &HeldKarp{
+		MemoryBudget:     DefaultHeldKarpMemoryBudget,
 		shortestDistance: math.MaxFloat64,
 	}
 }
 
-func (a *HeldKarp) Stop() {
-	a.running = false
+func (a *HeldKarp) Optimal() bool {
+	return a.optimal
 }
 
-func (a *HeldKarp) Solve(adjacency problem.Adjacency, updates chan problem.Cycle) {
-	a.running = true
-
-	set := make([]int, len(adjacency))
-	for i := range set {
-		set[i] = i
+// sets the parameters of held-karp, supported keys are:
+//   - memory: the memory budget for the tables in MiB
+func (a *HeldKarp) Configure(key, value string) error {
+	switch key {
+	case "memory":
+		mib, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid memory budget: %s", value)
+		}
+		a.MemoryBudget = mib << 20
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
 	}
+	return nil
+}
 
-	// prime table for hashing the sets
-	a.primes = GetPrimesTo(len(set) * 100)
+// solves asymmetric problems, the paths of the tables follow the direction of the distances
+func (a *HeldKarp) SupportsAsymmetric() bool {
+	return true
+}
 
-	// create table
-	table := make([]map[int]float64, len(set))
+// estimates the memory in bytes that the tables need to solve a problem with n points,
+// returns math.MaxUint64 if the tables can't be addressed at all
+func EstimateHeldKarpMemory(n int) uint64 {
+	if n < 2 {
+		return 0
+	}
 
-	// route from i through the empty set to 0
-	for i := range set {
-		table[i] = make(map[int]float64)
-		table[i][a.getHash(Set{})] = adjacency[i][0]
+	// point 0 is the fixed start, the remaining n-1 points form the subsets
+	m := uint64(n - 1)
+	if m >= 48 || m > math.MaxUint8 {
+		return math.MaxUint64
 	}
 
-	backtracking := make([]map[int]int, len(set))
-	for i := range set {
-		backtracking[i] = make(map[int]int)
+	return (1 << m) * m * heldKarpEntrySize
+}
+
+// tests if the tables for the given problem fit into the memory budget
+func (a *HeldKarp) Validate(adjacency problem.Adjacency) error {
+	required := EstimateHeldKarpMemory(len(adjacency))
+	if required > a.MemoryBudget {
+		return fmt.Errorf("held-karp needs %s to solve %d points, exceeds budget of %s",
+			formatBytes(required), len(adjacency), formatBytes(a.MemoryBudget))
 	}
+	return nil
+}
 
-	// for every subset of S\{0}
-	for _, subset := range PowerSet(set[1:]) {
+// solves the problem by dynamic programming over all subsets of points, where subsets are
+// represented as bitmasks. table[mask*m + k] holds the shortest distance of a path that starts at
+// point 0, visits every point in mask and ends at point k+1
+func (a *HeldKarp) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	a.optimal = false
+	n := len(adjacency)
 
-		// stopped by user
-		if !a.running {
-			break
+	if err := a.Validate(adjacency); err != nil {
+		return err
+	}
+	log.Printf("solving problemset with %d entries using held-karp, tables need %s",
+		n, formatBytes(EstimateHeldKarpMemory(n)))
+
+	// trivial problems, there is only a single cycle
+	if n < 3 {
+		a.shortestCycle = make(problem.Cycle, n)
+		for i := range a.shortestCycle {
+			a.shortestCycle[i] = i
 		}
-
-		if len(subset) == 0 {
-			continue
+		if n > 0 {
+			a.shortestDistance = cycleDistance(adjacency, a.shortestCycle)
+			updates <- Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Final: true, Optimal: true}
 		}
+		a.optimal = true
+		return nil
+	}
 
-		// for every i, find the shortest shortestDistance that goes from i through subset to 0
-		// e.g. choose a j from subset that connects to i so that shortestDistance is minimal
-	NextElement:
-		for _, i := range set {
+	m := n - 1
+	masks := uint(1) << uint(m)
+	table := make([]float64, int(masks)*m)
+	predecessors := make([]uint8, int(masks)*m)
 
-			// if i is element of subset, cancel
-			for _, j := range subset {
-				if i == j {
-					continue NextElement
-				}
+	// paths that go from 0 directly to k
+	for k := 0; k < m; k++ {
+		table[(1<<uint(k))*m+k] = adjacency[0][k+1]
+	}
+
+	// subsets are visited in ascending order, so every subset of mask has been calculated before mask
+	throttled := newThrottledUpdates(updates)
+	for mask := uint(1); mask < masks; mask++ {
+
+		// cancelled or time to report the share of subsets done, check only every once in a while
+		if mask&0x3ff == 0 {
+			if cancelled(ctx) {
+				throttled.Finish(Progress{Iteration: int(mask)})
+				return ctx.Err()
 			}
+			throttled.Report(Progress{
+				Iteration: int(mask),
+				Stats:     map[string]float64{"subsets done %": math.Round(1000*float64(mask)/float64(masks)) / 10},
+			})
+		}
+
+		// subsets with a single point were initialized above
+		if mask&(mask-1) == 0 {
+			continue
+		}
 
-			hash := a.getHash(subset)
+		// for every k in mask, find the j in mask\{k} so that the path through mask\{k} to j and then to k is minimal
+		for ks := mask; ks != 0; ks &= ks - 1 {
+			k := bits.TrailingZeros(ks)
+			previous := mask &^ (1 << uint(k))
+			row := int(previous) * m
 
-			//  try for every j in subset the route from i to j through subset so that the shortestDistance is minimal
-			minDistance := float64(math.MaxFloat64)
+			minDistance := math.MaxFloat64
 			minJ := 0
-			for _, j := range subset {
-
-				// subset\{j}
-				subsetNoJ := make([]int, len(subset)-1)
-				index := 0
-				for _, p := range subset {
-					if p == j {
-						continue
-					}
-					subsetNoJ[index] = p
-					index++
-				}
-				h := a.getHash(subsetNoJ)
-
-				dist := adjacency[i][j] + table[j][h]
+			for js := previous; js != 0; js &= js - 1 {
+				j := bits.TrailingZeros(js)
+				dist := table[row+j] + adjacency[j+1][k+1]
 				if dist < minDistance {
 					minDistance = dist
 					minJ = j
 				}
 			}
 
-			table[i][hash] = minDistance
-			backtracking[i][hash] = minJ
+			table[int(mask)*m+k] = minDistance
+			predecessors[int(mask)*m+k] = uint8(minJ)
 		}
 	}
 
-	if !a.running {
-		return
-	}
-
-	// backtracking
-	backtrackingSet := make(Set, len(set)-1)
-	for i := range backtrackingSet {
-		backtrackingSet[i] = i + 1
-	}
-
-	a.shortestCycle = make(problem.Cycle, len(set))
+	// close the cycle by going back from the last point to 0
+	full := masks - 1
+	a.shortestDistance = math.MaxFloat64
 	last := 0
-	for i := range set {
-		h := a.getHash(backtrackingSet)
-		a.shortestCycle[i] = backtracking[last][h]
-		last = a.shortestCycle[i]
-
-		tmpSet := make(Set, len(backtrackingSet)-1)
-
-		if len(tmpSet) == 0 {
-			break
+	for k := 0; k < m; k++ {
+		dist := table[int(full)*m+k] + adjacency[k+1][0]
+		if dist < a.shortestDistance {
+			a.shortestDistance = dist
+			last = k
 		}
-
-		index := 0
-		for j := range backtrackingSet {
-			if backtrackingSet[j] == a.shortestCycle[i] {
-				continue
-			}
-			tmpSet[index] = backtrackingSet[j]
-			index++
-		}
-		backtrackingSet = tmpSet
 	}
 
-	// get shortest shortestDistance from table
-	hShortestCycle := a.getHash(set[1:])
-	a.shortestDistance = table[0][hShortestCycle]
-
-	// done, write solution to channel
-	updates <- a.shortestCycle
-	close(updates)
-	a.running = false
-}
-
-func (a *HeldKarp) getHash(s Set) int {
-	hash := 1
-
-	for _, k := range s {
-		hash *= a.primes[k]
+	// backtracking, walk the predecessors from the last point back to 0
+	a.shortestCycle = make(problem.Cycle, n)
+	mask := full
+	for i := n - 1; i > 0; i-- {
+		a.shortestCycle[i] = last + 1
+		previous := int(predecessors[int(mask)*m+last])
+		mask &^= 1 << uint(last)
+		last = previous
 	}
+	a.shortestCycle[0] = 0
 
-	return hash
+	// done, write solution to channel
+	updates <- Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Iteration: int(masks), Final: true, Optimal: true}
+	a.optimal = true
+	return nil
 }
 
 func (a HeldKarp) String() string {
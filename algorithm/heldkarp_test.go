@@ -1,6 +1,7 @@
 package algorithm
 
 import (
+	"context"
 	"leistungsnachweis-graphiker/problem"
 	"math"
 	"testing"
@@ -17,19 +18,116 @@ func TestHeldKarp(t *testing.T) {
 
 	p := problem.NewProblem(points)
 	b := NewHeldKarp()
-	u := make(chan problem.Cycle, 10)
+	u := make(chan Progress, 10)
 
-	go b.Solve(p.Adjacency, u)
+	go b.Solve(context.Background(), p.Adjacency, u)
 
 	for {
-		cycle, hasMore := <-u
+		progress, hasMore := <-u
 		if !hasMore {
 			break
 		}
-		p.UpdateRoute(cycle)
+		if progress.Cycle != nil {
+			p.UpdateRoute(progress.Cycle)
+		}
 	}
 
 	if math.Round(p.ShortestDistance*100)/100 != 220.71 {
 		t.Fatalf("wrong distance: %f", p.ShortestDistance)
 	}
 }
+
+func TestHeldKarpMatchesBruteForce(t *testing.T) {
+	points := []problem.Point{
+		{X: 3, Y: 91}, {X: 74, Y: 12}, {X: 45, Y: 67}, {X: 18, Y: 30},
+		{X: 88, Y: 55}, {X: 61, Y: 94}, {X: 27, Y: 8}, {X: 96, Y: 81},
+		{X: 52, Y: 38},
+	}
+	p := problem.NewProblem(points)
+
+	solve := func(a Algorithm) float64 {
+		u := make(chan Progress, 10)
+		go a.Solve(context.Background(), p.Adjacency, u)
+		for progress := range u {
+			if progress.Cycle != nil {
+				p.UpdateRoute(progress.Cycle)
+			}
+		}
+		return p.ShortestDistance
+	}
+
+	bruteForce := solve(NewBruteForce())
+	heldKarp := solve(NewHeldKarp())
+
+	if math.Abs(bruteForce-heldKarp) > 1e-9 {
+		t.Fatalf("distances differ: bruteforce=%f, heldkarp=%f", bruteForce, heldKarp)
+	}
+}
+
+func TestHeldKarpMemoryBudget(t *testing.T) {
+	// 21 points -> 2^20 subsets * 20 endpoints * 9 bytes
+	if EstimateHeldKarpMemory(21) != (1<<20)*20*9 {
+		t.Fatalf("wrong memory estimate: %d", EstimateHeldKarpMemory(21))
+	}
+
+	h := NewHeldKarp()
+	h.MemoryBudget = 1 << 20
+	adjacency := make(problem.Adjacency, 21)
+	if h.Validate(adjacency) == nil {
+		t.Fatalf("expected problem to exceed memory budget")
+	}
+
+	// refused problems close the channel without sending a cycle
+	u := make(chan Progress, 1)
+	if err := h.Solve(context.Background(), adjacency, u); err == nil {
+		t.Fatalf("expected error for refused problem")
+	}
+	if _, hasMore := <-u; hasMore {
+		t.Fatalf("expected no cycle for refused problem")
+	}
+}
+
+func TestHeldKarpAsymmetric(t *testing.T) {
+	if d := solveProblem(t, NewHeldKarp(), oneWayRing(7)); d != 7 {
+		t.Fatalf("wrong distance on a one-way ring: %f", d)
+	}
+
+	// the shortest cycles of random asymmetric problems are usually longer in the other direction
+	reversedLonger := false
+	for seed := int64(1); seed <= 5; seed++ {
+		p := asymmetricProblem(8, seed)
+		a := NewHeldKarp()
+		heldKarp := solveProblem(t, a, p)
+		bruteForce := solveProblem(t, NewBruteForce(), asymmetricProblem(8, seed))
+		if heldKarp != bruteForce {
+			t.Fatalf("seed %d: distances differ: held-karp=%f, bruteforce=%f", seed, heldKarp, bruteForce)
+		}
+
+		reversed := make(problem.Cycle, len(a.shortestCycle))
+		for i, j := range a.shortestCycle {
+			reversed[len(reversed)-1-i] = j
+		}
+		reversedLonger = reversedLonger || p.Adjacency.Distance(reversed) > heldKarp
+	}
+	if !reversedLonger {
+		t.Fatal("the shortest cycles do not depend on the direction")
+	}
+}
+
+func TestHeldKarpCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// cancelled searches finish with a final event that is not optimal
+	a := NewHeldKarp()
+	u := make(chan Progress, 10)
+	go a.Solve(ctx, randomProblem(14, 1).Adjacency, u)
+
+	var last Progress
+	for progress := range u {
+		last = progress
+	}
+	if !last.Final || last.Optimal {
+		t.Fatalf("expected a final event that is not optimal, got %+v", last)
+	}
+}
@@ -0,0 +1,271 @@
+package algorithm
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// the shortest cycle found so far by a group of concurrent workers. workers read the distance to
+// prune their search and offer cycles, only strict improvements are forwarded to the updates-channel
+type incumbent struct {
+	mutex    sync.Mutex
+	distance uint64 // bits of a float64, accessed atomically
+	cycle    problem.Cycle
+	updates  chan Progress
+
+	// see breakTies, pending is set if the shortest cycle won a tie-break and was not sent yet
+	ties      bool
+	symmetric bool
+	pending   bool
+}
+
+func newIncumbent(updates chan Progress) *incumbent {
+	return &incumbent{
+		distance: math.Float64bits(math.MaxFloat64),
+		updates:  updates,
+	}
+}
+
+// returns the distance of the shortest cycle found so far
+func (b *incumbent) Distance() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&b.distance))
+}
+
+// returns the shortest cycle found so far, nil if there is none
+func (b *incumbent) Cycle() problem.Cycle {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.cycle
+}
+
+// makes the shortest cycle independent of the order in which the workers offer their cycles. of
+// two cycles whose distances differ by at most epsilon, the one whose canonical form comes first is
+// kept. the winner of a tie-break is not forwarded, the final event carries it instead. workers have
+// to offer every cycle that is not pruned, see Prunes
+func (b *incumbent) breakTies(symmetric bool) {
+	b.ties = true
+	b.symmetric = symmetric
+}
+
+// offers a cycle with the given distance, the cycle is copied and forwarded if it is shorter
+// than every cycle offered before. with a tie-break, see breakTies, it is kept without being
+// forwarded if it wins the tie-break instead. returns true if the cycle was accepted
+func (b *incumbent) Offer(cycle problem.Cycle, distance float64) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	tie := b.ties && b.cycle != nil && math.Abs(distance-b.Distance()) <= epsilon
+	if tie {
+		cycle = canonicalCycle(cycle, b.symmetric)
+		if !cycleLess(cycle, b.cycle) {
+			return false
+		}
+		distance = math.Min(distance, b.Distance())
+	} else if distance >= b.Distance() {
+		return false
+	} else if b.ties {
+		cycle = canonicalCycle(cycle, b.symmetric)
+	}
+
+	shortestCycle := make(problem.Cycle, len(cycle))
+	copy(shortestCycle, cycle)
+	b.cycle = shortestCycle
+	atomic.StoreUint64(&b.distance, math.Float64bits(distance))
+	b.pending = tie
+	if !tie {
+		b.updates <- Progress{Cycle: shortestCycle, Distance: distance}
+	}
+
+	return true
+}
+
+// tells if no cycle that starts with the points of partial, whose edges have the given distance,
+// can be accepted. with a tie-break, partial has to start at the smallest point, and a partial
+// cycle that is about as long as the shortest one is only searched if it comes first in the
+// canonical form
+func (b *incumbent) Prunes(partial problem.Cycle, distance float64) bool {
+	shortest := b.Distance()
+	if !b.ties || distance < shortest-epsilon || distance > shortest+epsilon {
+		return distance >= shortest
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.cycle == nil {
+		return false
+	}
+	for i, p := range partial {
+		if p != b.cycle[i] {
+			return p > b.cycle[i]
+		}
+	}
+	return false
+}
+
+// sends an event that only reports statistics, along with the shortest distance found so far
+func (b *incumbent) Report(progress Progress) {
+	progress.Cycle = nil
+	progress.Distance = b.Distance()
+	b.updates <- progress
+}
+
+// sends the final event, along with the shortest cycle if it won a tie-break and was not sent yet.
+// no cycles must be offered afterwards
+func (b *incumbent) Finish(progress Progress) {
+	progress.Final = true
+	progress.Cycle = nil
+	progress.Distance = b.Distance()
+	if b.pending {
+		progress.Cycle = b.Cycle()
+		b.pending = false
+	}
+	b.updates <- progress
+}
+
+// attaches the state of the search to progress events at most once per interval, embedded by
+// algorithms that implement Checkpointer
+type checkpoints struct {
+	checkpointInterval time.Duration
+	lastCheckpoint     time.Time
+}
+
+// sets the interval between two checkpoints, 0 disables checkpoints
+func (c *checkpoints) SetCheckpointInterval(interval time.Duration) {
+	c.checkpointInterval = interval
+}
+
+// tells if the state of the search has to be attached to the next event
+func (c *checkpoints) checkpointDue() bool {
+	if c.checkpointInterval <= 0 || time.Since(c.lastCheckpoint) < c.checkpointInterval {
+		return false
+	}
+	c.lastCheckpoint = time.Now()
+	return true
+}
+
+// tells if the state of the search has to be attached to the final event, which is the case when
+// checkpoints are enabled and the search was cancelled
+func (c *checkpoints) finalCheckpoint(ctx context.Context) bool {
+	return c.checkpointInterval > 0 && cancelled(ctx)
+}
+
+// starts the interval of the first checkpoint, called when the search starts
+func (c *checkpoints) startCheckpoints() {
+	c.lastCheckpoint = time.Now()
+}
+
+// source of randomness of a stochastic algorithm, embedded by algorithms that implement Randomized
+type randomness struct {
+	source *rand.Rand
+}
+
+// sets the source of randomness, which must not be shared with other goroutines
+func (r *randomness) SetRandom(random *rand.Rand) {
+	r.source = random
+}
+
+// returns the source of randomness, one seeded with the current time if none was set
+func (r *randomness) random() *rand.Rand {
+	if r.source == nil {
+		r.source = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return r.source
+}
+
+// derives an independent source of randomness, e.g. for a worker or a member of a portfolio
+func deriveRandom(random *rand.Rand) *rand.Rand {
+	return rand.New(rand.NewSource(random.Int63()))
+}
+
+// serializes the state of a search, failures are logged and result in an event without a state
+func marshalState(state interface{}) json.RawMessage {
+	bytes, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("failed to serialize the state of the search: %s", err)
+		return nil
+	}
+	return bytes
+}
+
+// minimum time between two cycles sent to the updates-channel by throttledUpdates
+const updateInterval = 50 * time.Millisecond
+
+// minimum time between two events that only report statistics
+const statisticsInterval = time.Second
+
+// forwards events to the updates-channel, but new cycles at most once per updateInterval and
+// statistics at most once per statisticsInterval so that fast algorithms don't flood the consumer.
+// cycles in between are held back, only the last one is kept
+type throttledUpdates struct {
+	updates        chan Progress
+	last           time.Time
+	lastStatistics time.Time
+	distance       float64
+	pending        *Progress
+}
+
+func newThrottledUpdates(updates chan Progress) *throttledUpdates {
+	return &throttledUpdates{updates: updates, lastStatistics: time.Now(), distance: math.MaxFloat64}
+}
+
+// sends an event with a new shortest cycle or holds it back, the cycle must not be modified afterwards
+func (u *throttledUpdates) Send(progress Progress) {
+	u.distance = progress.Distance
+	if time.Since(u.last) < updateInterval {
+		u.pending = &progress
+		return
+	}
+
+	u.send(progress)
+}
+
+// sends an event that only reports statistics, unless statistics were sent less than
+// statisticsInterval ago. a cycle that was held back is sent along with them
+func (u *throttledUpdates) Report(progress Progress) {
+	if !u.StatisticsDue() {
+		return
+	}
+
+	u.lastStatistics = time.Now()
+	u.send(u.merge(progress))
+}
+
+// tells if statistics would be sent, so that algorithms only calculate expensive statistics when needed
+func (u *throttledUpdates) StatisticsDue() bool {
+	return time.Since(u.lastStatistics) >= statisticsInterval
+}
+
+// sends an event with the state of the search, regardless of the time the last event was sent
+func (u *throttledUpdates) Checkpoint(progress Progress) {
+	u.send(u.merge(progress))
+}
+
+// sends the final event, along with the cycle that was held back last, if any
+func (u *throttledUpdates) Finish(progress Progress) {
+	progress.Final = true
+	u.send(u.merge(progress))
+}
+
+// adds the cycle that was held back to an event without a cycle
+func (u *throttledUpdates) merge(progress Progress) Progress {
+	progress.Cycle = nil
+	if u.pending != nil {
+		progress.Cycle = u.pending.Cycle
+	}
+	progress.Distance = u.distance
+	return progress
+}
+
+func (u *throttledUpdates) send(progress Progress) {
+	u.updates <- progress
+	u.last = time.Now()
+	u.pending = nil
+}
@@ -0,0 +1,55 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"reflect"
+	"testing"
+)
+
+func TestIncumbentBreaksTies(t *testing.T) {
+	// every cycle of four points has the same length, some are given as rotations or mirrors
+	cycles := []problem.Cycle{{2, 0, 3, 1}, {3, 2, 1, 0}, {1, 3, 0, 2}, {3, 1, 2, 0}}
+	orders := [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}, {1, 3, 0, 2}, {2, 0, 3, 1}}
+
+	// the cycle that is kept does not depend on the order of the offers
+	for _, order := range orders {
+		updates := make(chan Progress, len(cycles)+1)
+		best := newIncumbent(updates)
+		best.breakTies(true)
+		for k, i := range order {
+			best.Offer(cycles[i], 4+float64(k)*epsilon/10)
+		}
+		if cycle := best.Cycle(); !reflect.DeepEqual(cycle, problem.Cycle{0, 1, 2, 3}) {
+			t.Fatalf("order %v: kept %v, expected [0 1 2 3]", order, cycle)
+		}
+
+		// only the first cycle is forwarded, the final event carries the winner of the tie-break if it came later
+		best.Finish(Progress{})
+		close(updates)
+		if len(updates) != 2 {
+			t.Fatalf("order %v: expected 2 events, got %d", order, len(updates))
+		}
+		var last problem.Cycle
+		for progress := range updates {
+			if progress.Cycle != nil {
+				last = progress.Cycle
+			}
+		}
+		if !reflect.DeepEqual(last, problem.Cycle{0, 1, 2, 3}) {
+			t.Fatalf("order %v: last cycle %v is not the winner of the tie-break", order, last)
+		}
+	}
+
+	// shorter cycles win regardless of their form, without the tie-break the first cycle is kept
+	best := newIncumbent(make(chan Progress, len(cycles)))
+	best.breakTies(true)
+	best.Offer(cycles[1], 4)
+	if !best.Offer(cycles[0], 3) || !reflect.DeepEqual(best.Cycle(), problem.Cycle{0, 2, 1, 3}) {
+		t.Fatalf("shorter cycle was not accepted: %v", best.Cycle())
+	}
+	best = newIncumbent(make(chan Progress, len(cycles)))
+	best.Offer(cycles[0], 4)
+	if best.Offer(cycles[1], 4) || !reflect.DeepEqual(best.Cycle(), cycles[0]) {
+		t.Fatalf("cycle of the same length replaced the first one: %v", best.Cycle())
+	}
+}
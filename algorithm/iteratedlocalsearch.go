@@ -0,0 +1,163 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// acceptance criteria of iterated local search, decide if the search continues from a new local
+// optimum or goes back to the previous one
+const (
+	// only local optima that are not longer than the current one are accepted
+	AcceptBetter = "better"
+
+	// local optima that are at most the threshold longer than the current one are accepted
+	AcceptThreshold = "threshold"
+
+	// every local optimum is accepted, a random walk through the local optima
+	AcceptAlways = "always"
+)
+
+const (
+	// default share by which a local optimum may be longer than the current one to be accepted
+	DefaultAcceptanceThreshold = 0.02
+
+	// maximum distance, in positions, between the cuts of a double-bridge kick
+	iteratedKickSegment = 50
+)
+
+// improves a cycle with a local search until it reaches a local optimum, then perturbs it with a
+// double-bridge kick and improves it again. the acceptance criterion decides if the search goes on
+// from the new local optimum. kicks are applied until the algorithm is stopped or the configured
+// number of kicks is reached
+type IteratedLocalSearch struct {
+	warmStart
+	randomness
+	Neighbours       int
+	Kicks            int
+	Acceptance       string
+	Threshold        float64
+	search           localOptimizer
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewIteratedLocalSearch() *IteratedLocalSearch {
+	return &IteratedLocalSearch{
+		Neighbours:       DefaultNeighbours,
+		Acceptance:       AcceptBetter,
+		Threshold:        DefaultAcceptanceThreshold,
+		search:           NewLocalSearch(),
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of iterated local search, supported keys are:
+//   - search: the local search that is wrapped, 2opt, localsearch or linkernighan
+//   - neighbours: number of nearest neighbours considered for a move
+//   - kicks: number of kicks after which the algorithm finishes, 0 to kick until stopped
+//   - acceptance: better, threshold or always
+//   - threshold: share by which a local optimum may be longer to be accepted by threshold
+func (a *IteratedLocalSearch) Configure(key, value string) error {
+	switch key {
+	case "search":
+		search, err := localOptimizerFromString(value)
+		if err != nil {
+			return err
+		}
+		a.search = search
+	case "neighbours":
+		neighbours, err := strconv.Atoi(value)
+		if err != nil || neighbours < 1 {
+			return fmt.Errorf("invalid number of neighbours: %s", value)
+		}
+		a.Neighbours = neighbours
+	case "kicks":
+		kicks, err := strconv.Atoi(value)
+		if err != nil || kicks < 0 {
+			return fmt.Errorf("invalid number of kicks: %s", value)
+		}
+		a.Kicks = kicks
+	case "acceptance":
+		acceptance := strings.ToLower(value)
+		if acceptance != AcceptBetter && acceptance != AcceptThreshold && acceptance != AcceptAlways {
+			return fmt.Errorf("unknown acceptance criterion: %s", value)
+		}
+		a.Acceptance = acceptance
+	case "threshold":
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 {
+			return fmt.Errorf("invalid acceptance threshold: %s", value)
+		}
+		a.Threshold = threshold
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *IteratedLocalSearch) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using %s, %s acceptance", n, a, a.Acceptance)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		neighbours := nearestNeighbours(adjacency, a.Neighbours)
+		t := newTour(a.startCycle(adjacency))
+		a.search.optimizeTour(ctx, adjacency, t, neighbours, t.Cycle())
+
+		current := t.Cycle()
+		currentDistance := t.distance(adjacency)
+		a.shortestCycle = current
+		a.shortestDistance = currentDistance
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance})
+
+		random := a.random()
+		kick := 0
+		for ; n >= 8 && !cancelled(ctx) && (a.Kicks == 0 || kick < a.Kicks); kick++ {
+			a.search.optimizeTour(ctx, adjacency, t, neighbours, t.doubleBridge(random, iteratedKickSegment))
+
+			distance := t.distance(adjacency)
+			if distance < a.shortestDistance-epsilon {
+				a.shortestDistance = distance
+				a.shortestCycle = t.Cycle()
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance, Iteration: kick + 1})
+			}
+
+			if a.accept(distance, currentDistance) {
+				current = t.Cycle()
+				currentDistance = distance
+			} else {
+				t = newTour(current)
+			}
+			throttled.Report(Progress{Iteration: kick + 1, Stats: map[string]float64{"current distance": currentDistance}})
+		}
+
+		throttled.Finish(Progress{Iteration: kick, Stats: map[string]float64{"current distance": currentDistance}})
+	}
+
+	return ctx.Err()
+}
+
+func (a IteratedLocalSearch) String() string {
+	return fmt.Sprintf("Iterated Local Search (%s)", a.search)
+}
+
+// decides if the search continues from a local optimum with the given distance
+func (a *IteratedLocalSearch) accept(distance, currentDistance float64) bool {
+	switch a.Acceptance {
+	case AcceptAlways:
+		return true
+	case AcceptThreshold:
+		return distance < currentDistance*(1+a.Threshold)+epsilon
+	default:
+		return distance < currentDistance+epsilon
+	}
+}
@@ -0,0 +1,81 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestIteratedLocalSearch(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewIteratedLocalSearch(), p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestIteratedLocalSearchImproves(t *testing.T) {
+	p := randomProblem(300, 8)
+	localSearch := solveProblem(t, NewLocalSearch(), p)
+
+	for _, acceptance := range []string{AcceptBetter, AcceptThreshold, AcceptAlways} {
+		a := NewIteratedLocalSearch()
+		a.Acceptance = acceptance
+		a.Kicks = 500
+		if d := solveProblem(t, a, p); d >= localSearch {
+			t.Fatalf("%s: distance %f is not shorter than local search %f", acceptance, d, localSearch)
+		}
+	}
+}
+
+func TestIteratedLocalSearchStop(t *testing.T) {
+	p := randomProblem(100, 9)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	u := make(chan Progress, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewIteratedLocalSearch().Solve(ctx, p.Adjacency, u)
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-u:
+			if !ok {
+				if err := <-done; err != context.DeadlineExceeded {
+					t.Fatalf("expected the error of the context, got %v", err)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("iterated local search did not stop")
+		}
+	}
+}
+
+func TestIteratedLocalSearchConfigure(t *testing.T) {
+	a, err := FromString("ils:search=linkernighan:acceptance=threshold:threshold=0.05:kicks=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ils := a.(*IteratedLocalSearch)
+	if _, ok := ils.search.(*LinKernighan); !ok || ils.Acceptance != AcceptThreshold || ils.Threshold != 0.05 || ils.Kicks != 10 {
+		t.Fatalf("parameters not applied: %+v", ils)
+	}
+
+	if _, err := FromString("ils:search=annealing"); err == nil {
+		t.Fatalf("expected error for a search that is not a local search")
+	}
+}
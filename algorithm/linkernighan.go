@@ -0,0 +1,368 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// maximum number of sequential 2-opt moves that form a single lin-kernighan move
+const linKernighanDepth = 50
+
+// number of alternatives that are tried at the first levels of a lin-kernighan move if the
+// alternatives before did not find a shorter cycle, 0 tries every candidate. deeper levels only try
+// the best alternative
+var linKernighanBreadth = []int{0, 3}
+
+// number of alternate first steps that are tried for an edge at t1, see linKernighanSearch.alternate
+const linKernighanAlternates = 5
+
+// maximum distance, in positions, between the cuts of a double-bridge kick
+const linKernighanKickSegment = 50
+
+// improves a cycle with lin-kernighan moves, e.g. variable-depth sequences of 2-opt moves, until no
+// improving move is left. afterwards the cycle is perturbed by double-bridge kicks, after each kick
+// the cycle is improved again and kept if it is shorter than the shortest one. kicks are applied
+// until the algorithm is stopped or the configured number of kicks is reached
+type LinKernighan struct {
+	warmStart
+	randomness
+	Neighbours       int
+	Kicks            int
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+// a 2-opt move of a lin-kernighan move, replaces the edges (t1, t2), (t3, t4) by (t2, t3), (t4, t1)
+type linKernighanStep struct {
+	t1, t2, t3, t4 int
+}
+
+// a candidate for the next step, gain is the length of the removed edge (t3, t4) minus the length
+// of the added edge (t2, t3)
+type linKernighanCandidate struct {
+	t3, t4 int
+	gain   float64
+}
+
+// state of a single lin-kernighan search on a tour. the move that is searched has removed and added
+// the given edges, its shortest closed cycle is bestGain shorter and needs the first bestSteps steps
+type linKernighanSearch struct {
+	adjacency  problem.Adjacency
+	tour       *tour
+	neighbours [][]int
+	steps      []linKernighanStep
+	added      [][2]int
+	removed    [][2]int
+	bestGain   float64
+	bestSteps  int
+	candidates [][]linKernighanCandidate
+}
+
+func NewLinKernighan() *LinKernighan {
+	return &LinKernighan{
+		Neighbours:       DefaultNeighbours,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of lin-kernighan, supported keys are:
+//   - neighbours: number of nearest neighbours considered for a move
+//   - kicks: number of kicks after which the algorithm finishes, 0 to kick until stopped
+func (a *LinKernighan) Configure(key, value string) error {
+	switch key {
+	case "neighbours":
+		neighbours, err := strconv.Atoi(value)
+		if err != nil || neighbours < 1 {
+			return fmt.Errorf("invalid number of neighbours: %s", value)
+		}
+		a.Neighbours = neighbours
+	case "kicks":
+		kicks, err := strconv.Atoi(value)
+		if err != nil || kicks < 0 {
+			return fmt.Errorf("invalid number of kicks: %s", value)
+		}
+		a.Kicks = kicks
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *LinKernighan) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using lin-kernighan", n)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		search := &linKernighanSearch{
+			adjacency:  adjacency,
+			tour:       newTour(a.startCycle(adjacency)),
+			neighbours: nearestNeighbours(adjacency, a.Neighbours),
+		}
+
+		search.optimize(ctx, search.tour.cycle)
+		a.shortestCycle = search.tour.Cycle()
+		a.shortestDistance = search.tour.distance(adjacency)
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance})
+
+		random := a.random()
+		kick := 0
+		for ; n >= 8 && !cancelled(ctx) && (a.Kicks == 0 || kick < a.Kicks); kick++ {
+			search.optimize(ctx, search.tour.doubleBridge(random, linKernighanKickSegment))
+
+			distance := search.tour.distance(adjacency)
+			if distance < a.shortestDistance-epsilon {
+				a.shortestDistance = distance
+				a.shortestCycle = search.tour.Cycle()
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance, Iteration: kick + 1})
+			} else if distance > a.shortestDistance+epsilon {
+				// worse than before, go back to the shortest cycle
+				search.tour = newTour(a.shortestCycle)
+			}
+			throttled.Report(Progress{Iteration: kick + 1})
+		}
+
+		throttled.Finish(Progress{Iteration: kick})
+	}
+
+	return ctx.Err()
+}
+
+func (a *LinKernighan) optimizeTour(ctx context.Context, adjacency problem.Adjacency, t *tour, neighbours [][]int, points []int) {
+	search := &linKernighanSearch{adjacency: adjacency, tour: t, neighbours: neighbours}
+	search.optimize(ctx, points)
+}
+
+func (a LinKernighan) String() string {
+	return "Lin-Kernighan"
+}
+
+// applies lin-kernighan moves starting at the given points until there are no improving moves left
+// or ctx is cancelled. points whose edges change are examined again
+func (s *linKernighanSearch) optimize(ctx context.Context, points []int) {
+	n := len(s.tour.cycle)
+	if n < 5 {
+		return
+	}
+
+	queue := make([]int, 0, n)
+	active := make([]bool, n)
+	activate := func(p int) {
+		if !active[p] {
+			active[p] = true
+			queue = append(queue, p)
+		}
+	}
+	for _, p := range points {
+		activate(p)
+	}
+
+	for len(queue) > 0 && !cancelled(ctx) {
+		t1 := queue[0]
+		queue = queue[1:]
+		active[t1] = false
+
+		if s.improve(t1) {
+			for _, step := range s.steps {
+				activate(step.t1)
+				activate(step.t2)
+				activate(step.t3)
+				activate(step.t4)
+			}
+			s.steps = s.steps[:0]
+		}
+	}
+}
+
+// searches an improving lin-kernighan move that removes an edge at t1 and applies it. the chain of
+// steps is followed as deep as the gain allows, the move ends at the step with the best gain
+func (s *linKernighanSearch) improve(t1 int) bool {
+	for _, t2 := range []int{s.tour.next(t1), s.tour.prev(t1)} {
+		s.bestGain, s.bestSteps = epsilon, 0
+		s.added = s.added[:0]
+		s.removed = append(s.removed[:0], [2]int{t1, t2})
+		if !s.step(t1, t2, s.adjacency[t1][t2], 1) && !s.alternate(t1, t2) {
+			continue
+		}
+
+		for len(s.steps) > s.bestSteps {
+			s.undo()
+		}
+		return true
+	}
+	return false
+}
+
+// the edge (t1, t2) is about to be removed, gain is the sum of the removed minus the added edges so
+// far. adds an edge (t2, t3) and removes (t3, t4) so that (t4, t1) closes the cycle again. the
+// closed cycle is remembered if it is the shortest of the move so far, then (t4, t1) is about to be
+// removed in the next step. candidates are tried by the gain of their step, edges that were added
+// are not removed again and vice versa. returns true if the move found a shorter cycle, the steps
+// after the best one are still applied
+func (s *linKernighanSearch) step(t1, t2 int, gain float64, depth int) bool {
+	forward := s.tour.next(t1) == t2
+	for len(s.candidates) < depth {
+		s.candidates = append(s.candidates, nil)
+	}
+	candidates := s.candidates[depth-1][:0]
+
+	for _, t3 := range s.neighbours[t2] {
+		if gain-s.adjacency[t2][t3] <= epsilon {
+			break
+		}
+
+		// t3 must not be adjacent to t2, t4 is the neighbour of t3 on the side of t2
+		var t4 int
+		if forward {
+			if t3 == t1 || t3 == s.tour.next(t2) {
+				continue
+			}
+			t4 = s.tour.prev(t3)
+		} else {
+			if t3 == t1 || t3 == s.tour.prev(t2) {
+				continue
+			}
+			t4 = s.tour.next(t3)
+		}
+		if containsEdge(s.removed, t2, t3) || containsEdge(s.added, t3, t4) {
+			continue
+		}
+		candidates = append(candidates, linKernighanCandidate{t3: t3, t4: t4,
+			gain: s.adjacency[t3][t4] - s.adjacency[t2][t3]})
+	}
+	sort.Slice(candidates, func(x, y int) bool { return candidates[x].gain > candidates[y].gain })
+	s.candidates[depth-1] = candidates
+
+	breadth := 1
+	if depth <= len(linKernighanBreadth) {
+		breadth = linKernighanBreadth[depth-1]
+	}
+	if breadth == 0 || breadth > len(candidates) {
+		breadth = len(candidates)
+	}
+
+	added, removed := len(s.added), len(s.removed)
+	for _, c := range candidates[:breadth] {
+		g := gain - s.adjacency[t2][c.t3] + s.adjacency[c.t3][c.t4]
+		s.apply(linKernighanStep{t1: t1, t2: t2, t3: c.t3, t4: c.t4})
+		s.added = append(s.added, [2]int{t2, c.t3})
+		s.removed = append(s.removed, [2]int{c.t3, c.t4})
+		if closing := g - s.adjacency[c.t4][t1]; closing > s.bestGain {
+			s.bestGain, s.bestSteps = closing, len(s.steps)
+		}
+
+		if depth < linKernighanDepth {
+			s.step(t1, c.t4, g, depth+1)
+		}
+		if s.bestSteps > 0 {
+			return true
+		}
+		s.undo()
+		s.added, s.removed = s.added[:added], s.removed[:removed]
+	}
+
+	return false
+}
+
+// the alternate first step of lin-kernighan, tried if the regular one finds no shorter cycle. t4 is
+// the neighbour of t3 that does not close the cycle, instead the path from t2 to t3 is split between
+// t5 and t6. the paths [t2, t5] and [t6, t3] are exchanged, which is a sequential 3-opt move made of
+// three 2-opt steps, and the move continues with the removal of (t6, t1)
+func (s *linKernighanSearch) alternate(t1, t2 int) bool {
+	n := len(s.tour.cycle)
+	forward := s.tour.next(t1) == t2
+	successor, distance := s.tour.next, func(from, to int) int {
+		return (s.tour.positions[to] - s.tour.positions[from] + n) % n
+	}
+	if !forward {
+		successor, distance = s.tour.prev, func(from, to int) int {
+			return (s.tour.positions[from] - s.tour.positions[to] + n) % n
+		}
+	}
+
+	tried := 0
+	for _, t3 := range s.neighbours[t2] {
+		g1 := s.adjacency[t1][t2] - s.adjacency[t2][t3]
+		if g1 <= epsilon {
+			break
+		}
+		t4 := successor(t3)
+		if t3 == t1 || t4 == t1 {
+			continue
+		}
+
+		for _, t5 := range s.neighbours[t4] {
+			g2 := g1 + s.adjacency[t3][t4] - s.adjacency[t4][t5]
+			if g2 <= epsilon {
+				break
+			}
+
+			// t5 lies on the path from t2 to t3, but is not t3
+			if distance(t2, t5) >= distance(t2, t3) {
+				continue
+			}
+			t6 := successor(t5)
+			g3 := g2 + s.adjacency[t5][t6]
+
+			s.apply(linKernighanStep{t1: t2, t2: t1, t3: t3, t4: t4})
+			s.apply(linKernighanStep{t1: t3, t2: t1, t3: t6, t4: t5})
+			s.apply(linKernighanStep{t1: t5, t2: t3, t3: t2, t4: t4})
+			s.added = append(s.added[:0], [2]int{t2, t3}, [2]int{t4, t5})
+			s.removed = append(s.removed[:1], [2]int{t3, t4}, [2]int{t5, t6})
+			if closing := g3 - s.adjacency[t6][t1]; closing > s.bestGain {
+				s.bestGain, s.bestSteps = closing, len(s.steps)
+			}
+
+			s.step(t1, t6, g3, len(linKernighanBreadth)+1)
+			if s.bestSteps > 0 {
+				return true
+			}
+			for k := 0; k < 3; k++ {
+				s.undo()
+			}
+
+			if tried++; tried >= linKernighanAlternates {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// tells if the undirected edge (i, j) is one of the edges
+func containsEdge(edges [][2]int, i, j int) bool {
+	for _, e := range edges {
+		if e[0] == i && e[1] == j || e[0] == j && e[1] == i {
+			return true
+		}
+	}
+	return false
+}
+
+// applies a step by reversing the path from t2 to t4 that does not contain t1
+func (s *linKernighanSearch) apply(step linKernighanStep) {
+	if s.tour.next(step.t1) == step.t2 {
+		s.tour.reverse(step.t2, step.t4)
+	} else {
+		s.tour.reverse(step.t4, step.t2)
+	}
+	s.steps = append(s.steps, step)
+}
+
+// reverts the last step by reversing the path from t4 to t2 that does not contain t1
+func (s *linKernighanSearch) undo() {
+	step := s.steps[len(s.steps)-1]
+	s.steps = s.steps[:len(s.steps)-1]
+	if s.tour.next(step.t1) == step.t4 {
+		s.tour.reverse(step.t4, step.t2)
+	} else {
+		s.tour.reverse(step.t2, step.t4)
+	}
+}
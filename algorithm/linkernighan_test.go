@@ -0,0 +1,60 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLinKernighan(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewLinKernighan(), p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestLinKernighanOptimal(t *testing.T) {
+	p := randomProblem(14, 3)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	a := NewLinKernighan()
+	a.Kicks = 100
+	if d := solveProblem(t, a, p); math.Abs(d-optimal) > 1e-9 {
+		t.Fatalf("distance %f differs from optimal distance %f", d, optimal)
+	}
+}
+
+func TestLinKernighanStop(t *testing.T) {
+	p := randomProblem(1000, 4)
+
+	// without a limit of kicks, lin-kernighan only stops when it is cancelled. how far it gets
+	// depends on the machine, so only the cycle it stops with is checked by solveProblemContext
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	solveProblemContext(t, ctx, NewLinKernighan(), p)
+	if ctx.Err() == nil {
+		t.Fatal("lin-kernighan stopped before it was cancelled")
+	}
+	if len(p.ShortestRoute) != len(p.Points) {
+		t.Fatalf("no cycle after cancelling lin-kernighan")
+	}
+
+	// with a fixed number of kicks the result does not depend on the machine
+	twoOpt := solveProblem(t, NewTwoOpt(), p)
+	a := NewLinKernighan()
+	a.Kicks = 20
+	if d := solveProblem(t, a, p); d >= twoOpt {
+		t.Fatalf("no improvement over 2-opt %f: %f", twoOpt, d)
+	}
+}
@@ -0,0 +1,256 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// default number of nearest neighbours that are considered for a move
+const DefaultNeighbours = 10
+
+// maximum length of the segments that are moved by or-opt
+const orOptSegmentLength = 3
+
+// a local search that improves a tour in place, wrapped by iterated and guided local search.
+// the search starts at the given points and continues until there is no improving move left or
+// ctx is cancelled
+type localOptimizer interface {
+	Algorithm
+	optimizeTour(ctx context.Context, adjacency problem.Adjacency, t *tour, neighbours [][]int, points []int)
+}
+
+// creates the local search that is wrapped by a driver from its specification, e.g. "2opt"
+func localOptimizerFromString(spec string) (localOptimizer, error) {
+	algorithm, err := FromString(spec)
+	if err != nil {
+		return nil, err
+	}
+	optimizer, ok := algorithm.(localOptimizer)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a local search", algorithm)
+	}
+	return optimizer, nil
+}
+
+// improves a cycle with 2-opt and or-opt moves until no improving move is left. moves are only
+// searched among the nearest neighbours of a point. points whose neighbourhood did not change
+// since they were last examined are skipped (don't-look bits)
+type LocalSearch struct {
+	warmStart
+	Neighbours       int
+	OrOpt            bool
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewLocalSearch() *LocalSearch {
+	return &LocalSearch{
+		Neighbours:       DefaultNeighbours,
+		OrOpt:            true,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// creates a local search that only applies 2-opt moves
+func NewTwoOpt() *LocalSearch {
+	a := NewLocalSearch()
+	a.OrOpt = false
+	return a
+}
+
+// sets the parameters of the local search, supported keys are:
+//   - neighbours: number of nearest neighbours considered for a move
+//   - oropt: false to only apply 2-opt moves
+func (a *LocalSearch) Configure(key, value string) error {
+	switch key {
+	case "neighbours":
+		neighbours, err := strconv.Atoi(value)
+		if err != nil || neighbours < 1 {
+			return fmt.Errorf("invalid number of neighbours: %s", value)
+		}
+		a.Neighbours = neighbours
+	case "oropt":
+		orOpt, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for oropt: %s", value)
+		}
+		a.OrOpt = orOpt
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *LocalSearch) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+	log.Printf("solving problemset with %d entries using %s", n, a)
+
+	if n > 0 {
+		t := newTour(a.startCycle(adjacency))
+		throttled := newThrottledUpdates(updates)
+		moves := 0
+		improved := func() {
+			moves++
+			a.shortestCycle = t.Cycle()
+			throttled.Send(Progress{Cycle: a.shortestCycle, Distance: t.distance(adjacency), Iteration: moves})
+		}
+
+		a.shortestCycle = t.Cycle()
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: t.distance(adjacency)})
+		a.optimize(ctx, adjacency, t, nearestNeighbours(adjacency, a.Neighbours), t.Cycle(), improved)
+		a.shortestDistance = t.distance(adjacency)
+		throttled.Finish(Progress{Iteration: moves})
+	}
+
+	return ctx.Err()
+}
+
+func (a *LocalSearch) optimizeTour(ctx context.Context, adjacency problem.Adjacency, t *tour, neighbours [][]int, points []int) {
+	a.optimize(ctx, adjacency, t, neighbours, points, func() {})
+}
+
+// applies improving moves to the tour, starting at the given points, until there are none left or
+// ctx is cancelled. improved is called after every move
+func (a *LocalSearch) optimize(ctx context.Context, adjacency problem.Adjacency, t *tour, neighbours [][]int, points []int, improved func()) {
+	n := len(t.cycle)
+	if n < 5 {
+		return
+	}
+
+	// every point starts active, points are re-activated when an edge at them changes
+	queue := make([]int, 0, n)
+	active := make([]bool, n)
+	activate := func(points ...int) {
+		for _, p := range points {
+			if !active[p] {
+				active[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	activate(points...)
+
+	for len(queue) > 0 && !cancelled(ctx) {
+		p := queue[0]
+		queue = queue[1:]
+		active[p] = false
+
+		if changed := twoOptMove(adjacency, t, neighbours, p); changed != nil {
+			activate(changed...)
+			improved()
+			continue
+		}
+
+		if a.OrOpt {
+			if changed := orOptMove(adjacency, t, neighbours, p); changed != nil {
+				activate(changed...)
+				improved()
+			}
+		}
+	}
+}
+
+// searches an improving 2-opt move that replaces an edge at point a and applies the first one
+// found. returns the endpoints of the changed edges, nil if there was no improving move
+func twoOptMove(adjacency problem.Adjacency, t *tour, neighbours [][]int, a int) []int {
+	// a and its successor b, replace (a, b) and (c, d) by (a, c) and (b, d)
+	b := t.next(a)
+	for _, c := range neighbours[a] {
+		gain := adjacency[a][b] - adjacency[a][c]
+		if gain <= epsilon {
+			break
+		}
+		d := t.next(c)
+		if c == b || d == a {
+			continue
+		}
+		if gain+adjacency[c][d]-adjacency[b][d] > epsilon {
+			t.reverse(b, c)
+			return []int{a, b, c, d}
+		}
+	}
+
+	// a and its predecessor b, replace (b, a) and (d, c) by (c, a) and (d, b)
+	b = t.prev(a)
+	for _, c := range neighbours[a] {
+		gain := adjacency[b][a] - adjacency[a][c]
+		if gain <= epsilon {
+			break
+		}
+		d := t.prev(c)
+		if c == b || d == a {
+			continue
+		}
+		if gain+adjacency[d][c]-adjacency[d][b] > epsilon {
+			t.reverse(a, d)
+			return []int{a, b, c, d}
+		}
+	}
+
+	return nil
+}
+
+// searches an improving or-opt move that moves a segment of up to three points starting at point
+// a between two neighbouring points, possibly reversed, and applies the first one found. returns
+// the endpoints of the changed edges, nil if there was no improving move
+func orOptMove(adjacency problem.Adjacency, t *tour, neighbours [][]int, a int) []int {
+	n := len(t.cycle)
+	for length := 1; length <= orOptSegmentLength && length < n-2; length++ {
+
+		// the segment goes from first to last, it is preceded by prev and followed by next
+		first, last := a, a
+		for k := 1; k < length; k++ {
+			last = t.next(last)
+		}
+		prev, next := t.prev(first), t.next(last)
+		removeGain := adjacency[prev][first] + adjacency[last][next] - adjacency[prev][next]
+		if removeGain <= epsilon {
+			continue
+		}
+
+		inSegment := func(p int) bool {
+			return (t.positions[p]-t.positions[first]+n)%n < length
+		}
+
+		// insert between c and its successor, where c is a neighbour of either end of the segment
+		for _, end := range []int{first, last} {
+			for _, neighbour := range neighbours[end] {
+				if adjacency[end][neighbour] >= removeGain {
+					break
+				}
+
+				for _, c := range []int{neighbour, t.prev(neighbour)} {
+					d := t.next(c)
+					if inSegment(c) || inSegment(d) {
+						continue
+					}
+
+					base := adjacency[c][d]
+					if gain := removeGain + base - adjacency[c][first] - adjacency[last][d]; gain > epsilon {
+						t.move(first, last, c, false)
+						return []int{prev, next, first, last, c, d}
+					}
+					if gain := removeGain + base - adjacency[c][last] - adjacency[first][d]; gain > epsilon {
+						t.move(first, last, c, true)
+						return []int{prev, next, first, last, c, d}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a LocalSearch) String() string {
+	if !a.OrOpt {
+		return "2-opt"
+	}
+	return "2-opt and Or-opt"
+}
@@ -0,0 +1,70 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// generates a problem with n random points in [0, 1000), the points are shuffled with the same seed
+func randomProblem(n int, seed int64) *problem.Problem {
+	r := rand.New(rand.NewSource(seed))
+	points := make([]problem.Point, n)
+	for i := range points {
+		points[i] = problem.Point{X: math.Floor(r.Float64() * 1000), Y: math.Floor(r.Float64() * 1000)}
+	}
+	return problem.NewProblemWithRandom(points, r)
+}
+
+func TestLocalSearch(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewLocalSearch(), p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestLocalSearchTwoOptOptimal(t *testing.T) {
+	p := randomProblem(200, 1)
+
+	a := NewLocalSearch()
+	a.Neighbours = len(p.Points)
+	solveProblem(t, a, p)
+
+	// with all points as neighbours, there must not be any improving 2-opt move left
+	cycle := a.shortestCycle
+	n := len(cycle)
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			a, b := cycle[i], cycle[i+1]
+			c, d := cycle[j], cycle[(j+1)%n]
+			if a == d {
+				continue
+			}
+			gain := p.Adjacency[a][b] + p.Adjacency[c][d] - p.Adjacency[a][c] - p.Adjacency[b][d]
+			if gain > 1e-6 {
+				t.Fatalf("improving 2-opt move left: (%d, %d), (%d, %d), gain %f", a, b, c, d, gain)
+			}
+		}
+	}
+}
+
+func TestLocalSearchImproves(t *testing.T) {
+	p := randomProblem(2000, 2)
+	nearestNeighbour := cycleDistance(p.Adjacency, NearestNeighbour(p.Adjacency, 0))
+
+	twoOpt := solveProblem(t, NewTwoOpt(), p)
+	orOpt := solveProblem(t, NewLocalSearch(), p)
+
+	if twoOpt >= nearestNeighbour || orOpt >= nearestNeighbour {
+		t.Fatalf("no improvement over nearest neighbour %f: 2-opt %f, or-opt %f", nearestNeighbour, twoOpt, orOpt)
+	}
+}
@@ -1,11 +1,28 @@
 package algorithm
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"math/bits"
 	"sort"
+
+	"leistungsnachweis-graphiker/problem"
 )
 
+// tolerance when comparing distances, protects against rounding errors
+const epsilon = 1e-9
+
+// tells if the context was cancelled, without blocking
+func cancelled(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
 // uses the sieve of atkins to generate
 // a list of all primes in [2, upperBound]
 // returns the primes in ascending order
@@ -92,3 +109,65 @@ func PowerSet(s Set) []Set {
 
 	return results
 }
+
+// formats an amount of bytes in a human readable way, e.g. 1536 -> "1.5 KiB"
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// calculates the length of a cycle, including the way back from the last to the first point
+func cycleDistance(adjacency problem.Adjacency, cycle problem.Cycle) float64 {
+	var distance float64
+	for i := range cycle {
+		if i == len(cycle)-1 {
+			distance += adjacency[cycle[i]][cycle[0]]
+		} else {
+			distance += adjacency[cycle[i]][cycle[i+1]]
+		}
+	}
+	return distance
+}
+
+// rotates a cycle to start at its smallest point. the mirror of a cycle of a symmetric problem is the
+// same cycle, it continues with the smaller of the two neighbours. equal cycles have equal representations
+func canonicalCycle(cycle problem.Cycle, symmetric bool) problem.Cycle {
+	n := len(cycle)
+	start := 0
+	for i, p := range cycle {
+		if p < cycle[start] {
+			start = i
+		}
+	}
+
+	step := 1
+	if symmetric && n > 2 && cycle[(start+n-1)%n] < cycle[(start+1)%n] {
+		step = n - 1
+	}
+
+	canonical := make(problem.Cycle, n)
+	for i := range canonical {
+		canonical[i] = cycle[(start+i*step)%n]
+	}
+	return canonical
+}
+
+// compares two cycles of the same length lexicographically
+func cycleLess(first, second problem.Cycle) bool {
+	for i := range first {
+		if first[i] != second[i] {
+			return first[i] < second[i]
+		}
+	}
+	return false
+}
@@ -1,12 +1,16 @@
 package algorithm
 
 import (
+	"context"
+	"log"
+	"math"
+
 	"leistungsnachweis-graphiker/problem"
-	"sort"
 )
 
+// walks a minimum spanning tree in preorder, skipping points that were already visited. by the
+// triangle inequality the resulting cycle is at most twice as long as the shortest one
 type Mst struct {
-	running          bool
 	shortestDistance float64
 	shortestCycle    problem.Cycle
 }
@@ -21,92 +25,93 @@ func NewMst() *Mst {
 	return &Mst{}
 }
 
-func (a *Mst) Stop() {
-	a.running = false
+func (a *Mst) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	log.Printf("solving problemset with %d entries using minimum spanning tree", len(adjacency))
+
+	if len(adjacency) > 0 {
+		tree := minimumSpanningTree(adjacency)
+		a.shortestCycle = preorder(len(adjacency), tree)
+		a.shortestDistance = cycleDistance(adjacency, a.shortestCycle)
+		updates <- Progress{Cycle: a.shortestCycle, Distance: a.shortestDistance, Final: true}
+	}
+
+	return nil
 }
 
-func (a *Mst) Solve(adjacency problem.Adjacency, updates chan problem.Cycle) {
-	a.running = true
+func (a Mst) String() string {
+	return "Minimum Spanning Tree"
+}
 
-	// generate all edges
-	edges := make([]edge, 0)
+// calculates a minimum spanning tree using prim's algorithm, returns its n-1 edges
+func minimumSpanningTree(adjacency problem.Adjacency) []edge {
+	n := len(adjacency)
+	tree := make([]edge, 0, n)
+	if n == 0 {
+		return tree
+	}
 
-	for i := 0; i < len(adjacency); i++ {
-		for j := i + 1; j < len(adjacency); j++ {
-			edges = append(edges, edge{i: i, j: j, dist: adjacency[i][j]})
-		}
+	inTree := make([]bool, n)
+	minEdge := make([]float64, n)
+	parent := make([]int, n)
+	for i := range minEdge {
+		minEdge[i] = math.MaxFloat64
+		parent[i] = -1
 	}
+	minEdge[0] = 0
 
-	// sort by weight
-	sort.Slice(edges, func(i, j int) bool { return edges[i].dist < edges[j].dist })
-
-	// kruskal's algorithm
-	mst := make([]edge, 0)
-	for len(edges) > 0 {
-		candidate := edges[0]
-
-		// test for shortestCycle
-		i, j := false, false
-		for _, edge := range mst {
-			if edge.i == candidate.i {
-				i = true
-			} else if edge.j == candidate.j {
-				j = true
+	for range adjacency {
+		next := -1
+		for i := range adjacency {
+			if !inTree[i] && (next == -1 || minEdge[i] < minEdge[next]) {
+				next = i
 			}
 		}
 
-		// shortestCycle detected, discard and continue
-		if i && j {
-			edges = edges[1:]
-			continue
+		inTree[next] = true
+		if parent[next] != -1 {
+			tree = append(tree, edge{i: parent[next], j: next, dist: adjacency[parent[next]][next]})
 		}
 
-		// otherwise add to mst
-		mst = append(mst, candidate)
-		edges = edges[1:]
+		for i := range adjacency {
+			if !inTree[i] && adjacency[next][i] < minEdge[i] {
+				minEdge[i] = adjacency[next][i]
+				parent[i] = next
+			}
+		}
 	}
 
-	// generate shortestCycle
-	current := 0
-	a.shortestCycle = problem.Cycle{current}
-	visited := make([]bool, len(adjacency))
-	visited[0] = true
-	v := 1
-	for v < len(adjacency) {
-		for _, e := range mst {
-			if e.i != current && e.j != current {
-				continue
-			}
+	return tree
+}
 
-			if e.i == current {
-				if visited[e.j] {
-					current = e.j
-				} else {
-					visited[e.j] = true
-					a.shortestCycle = append(a.shortestCycle, e.j)
-					v++
-				}
-			} else if e.j == current {
-				if visited[e.i] {
-					current = e.i
-				} else {
-					visited[e.i] = true
-					a.shortestCycle = append(a.shortestCycle, e.i)
-					v++
-				}
-			}
-		}
+// visits the points of a tree in preorder, starting at point 0
+func preorder(n int, tree []edge) problem.Cycle {
+	neighbours := make([][]int, n)
+	for _, e := range tree {
+		neighbours[e.i] = append(neighbours[e.i], e.j)
+		neighbours[e.j] = append(neighbours[e.j], e.i)
 	}
 
-	for i := range a.shortestCycle {
-		if i == len(a.shortestCycle)-1 {
-			a.shortestDistance += adjacency[a.shortestCycle[i]][a.shortestCycle[0]]
-		} else {
-			a.shortestDistance += adjacency[a.shortestCycle[i]][a.shortestCycle[i+1]]
+	cycle := make(problem.Cycle, 0, n)
+	visited := make([]bool, n)
+	stack := []int{0}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[current] {
+			continue
+		}
+
+		visited[current] = true
+		cycle = append(cycle, current)
+
+		// push in reverse, so the neighbours are visited in the order they were added
+		for k := len(neighbours[current]) - 1; k >= 0; k-- {
+			if !visited[neighbours[current][k]] {
+				stack = append(stack, neighbours[current][k])
+			}
 		}
 	}
 
-	updates <- a.shortestCycle
-	close(updates)
-	a.running = false
+	return cycle
 }
@@ -0,0 +1,108 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+const (
+	TestProblemFileGermany   = "../samples/germany13.json"
+	TestProblemFileWorkpiece = "../samples/workpiece.json"
+)
+
+// solves a problem and returns the distance of the last cycle, fails if a cycle is not a permutation
+func solveProblem(t *testing.T, a Algorithm, p *problem.Problem) float64 {
+	return solveProblemContext(t, context.Background(), a, p)
+}
+
+// solves a problem until it is finished or ctx is cancelled, see solveProblem
+func solveProblemContext(t *testing.T, ctx context.Context, a Algorithm, p *problem.Problem) float64 {
+	u := make(chan Progress, 10)
+	go a.Solve(ctx, p.Adjacency, u)
+
+	var last Progress
+	for progress := range u {
+		if last.Final {
+			t.Fatalf("%s: sent an event after the final one", a)
+		}
+		last = progress
+
+		cycle := progress.Cycle
+		if cycle == nil {
+			continue
+		}
+		if len(cycle) != len(p.Points) {
+			t.Fatalf("%s: cycle has wrong length %d", a, len(cycle))
+		}
+		visited := make([]bool, len(cycle))
+		for _, i := range cycle {
+			if visited[i] {
+				t.Fatalf("%s: cycle visits point %d twice", a, i)
+			}
+			visited[i] = true
+		}
+		if math.Abs(progress.Distance-p.Adjacency.Distance(cycle)) > 1e-6 {
+			t.Fatalf("%s: distance %f does not match the cycle", a, progress.Distance)
+		}
+		p.UpdateRoute(cycle)
+	}
+	if len(p.Points) > 0 && !last.Final {
+		t.Fatalf("%s: the last event is not final", a)
+	}
+
+	return p.ShortestDistance
+}
+
+// solves a problem and returns the events that carry a state, the final event is always returned
+func checkpointEvents(t *testing.T, ctx context.Context, a Algorithm, p *problem.Problem) []Progress {
+	u := make(chan Progress, 10)
+	go a.Solve(ctx, p.Adjacency, u)
+
+	events := make([]Progress, 0)
+	for progress := range u {
+		if progress.State != nil || progress.Final {
+			events = append(events, progress)
+		}
+	}
+	if len(events) == 0 || !events[len(events)-1].Final {
+		t.Fatalf("%s: the last event is not final", a)
+	}
+	return events
+}
+
+// compares the distance of a heuristic with the optimal distance on the sample problems
+func testApproximation(t *testing.T, a func() Algorithm, factor float64) {
+	for _, file := range []string{TestProblemFileGermany, TestProblemFileWorkpiece} {
+		p, err := problem.FromFile(file)
+		if err != nil {
+			t.Fatalf("failed to load problem from file=%s, err=%s", file, err)
+		}
+
+		optimal := solveProblem(t, NewBranchAndBound(), &p)
+		heuristic := solveProblem(t, a(), &p)
+
+		if heuristic > factor*optimal {
+			t.Fatalf("%s: distance %f exceeds %.1f * %f", file, heuristic, factor, optimal)
+		}
+	}
+}
+
+func TestMst(t *testing.T) {
+	testApproximation(t, func() Algorithm { return NewMst() }, 2)
+}
+
+func TestMstSquare(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewMst(), p); math.Round(d) != 200 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
@@ -0,0 +1,334 @@
+package algorithm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// races several algorithms on the same problem. only cycles that are shorter than every cycle found
+// by any member are forwarded. as soon as an exact member finishes with a proof of optimality, the
+// other members are cancelled
+type Portfolio struct {
+	optimal          bool
+	members          []Algorithm
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewPortfolio(members ...Algorithm) *Portfolio {
+	return &Portfolio{
+		members:          members,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+func (a *Portfolio) Optimal() bool {
+	return a.optimal
+}
+
+// passes the coordinates of the points to the members that need them
+func (a *Portfolio) SetPoints(points []problem.Point) {
+	for _, member := range a.members {
+		if geometric, ok := member.(Geometric); ok {
+			geometric.SetPoints(points)
+		}
+	}
+}
+
+// passes the channel for snapshots of the pheromones to the members that keep pheromones
+func (a *Portfolio) SetPheromones(pheromones chan<- [][]float64) {
+	for _, member := range a.members {
+		if source, ok := member.(PheromoneSource); ok {
+			source.SetPheromones(pheromones)
+		}
+	}
+}
+
+// passes the initial cycle to the members that are able to start from it
+func (a *Portfolio) SetInitialCycle(cycle problem.Cycle) {
+	for _, member := range a.members {
+		if warmStarter, ok := member.(WarmStarter); ok {
+			warmStarter.SetInitialCycle(cycle)
+		}
+	}
+}
+
+// passes an independent source of randomness, derived from the given one, to every member that is
+// stochastic. members run concurrently and must not share a source
+func (a *Portfolio) SetRandom(random *rand.Rand) {
+	for _, member := range a.members {
+		if randomized, ok := member.(Randomized); ok {
+			randomized.SetRandom(deriveRandom(random))
+		}
+	}
+}
+
+// supports asymmetric problems only if every member does, the others would report wrong distances
+// or cycles that are far from the shortest one
+func (a *Portfolio) SupportsAsymmetric() bool {
+	for _, member := range a.members {
+		if !supportsAsymmetric(member) {
+			return false
+		}
+	}
+	return true
+}
+
+// refuses the problem only if no member is able to solve it, members that are not able to solve
+// it finish without sending a cycle
+func (a *Portfolio) Validate(adjacency problem.Adjacency) error {
+	var refused []string
+	for _, member := range a.members {
+		if validator, ok := member.(Validator); ok {
+			if err := validator.Validate(adjacency); err != nil {
+				refused = append(refused, err.Error())
+			}
+		}
+	}
+
+	if len(refused) == len(a.members) {
+		return errors.New("no member of the portfolio can solve the problem: " + strings.Join(refused, ", "))
+	}
+	for _, reason := range refused {
+		log.Printf("member of the portfolio refused the problem: %s", reason)
+	}
+	return nil
+}
+
+func (a *Portfolio) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	a.optimal = false
+	log.Printf("solving problemset with %d entries using %s", len(adjacency), a)
+
+	// cancelled by the caller or as soon as a member proved the shortest cycle
+	membersCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// members run concurrently, the tie-break keeps the shortest cycle independent of their timing
+	best := newIncumbent(updates)
+	best.breakTies(adjacency.Symmetric())
+	errs := make([]error, len(a.members))
+	finals := make([]Progress, len(a.members))
+	var optimal int32
+	wg := sync.WaitGroup{}
+	wg.Add(len(a.members))
+	for i, member := range a.members {
+		go func(i int, member Algorithm) {
+			defer wg.Done()
+			memberUpdates := make(chan Progress, 10)
+			done := make(chan error, 1)
+			go func() {
+				done <- member.Solve(membersCtx, adjacency, memberUpdates)
+			}()
+
+			for progress := range memberUpdates {
+				forward(adjacency, best, member, progress)
+				if progress.Final {
+					finals[i] = progress
+				}
+			}
+
+			errs[i] = <-done
+			if errs[i] == nil && finals[i].Optimal {
+				log.Printf("%s proved the shortest cycle, cancelling the portfolio", member)
+				atomic.StoreInt32(&optimal, 1)
+				cancel()
+			}
+		}(i, member)
+	}
+	wg.Wait()
+
+	a.optimal = atomic.LoadInt32(&optimal) == 1
+	a.shortestDistance = best.Distance()
+	a.shortestCycle = best.Cycle()
+	var lowerBound float64
+	for _, final := range finals {
+		lowerBound = math.Max(lowerBound, final.LowerBound)
+	}
+	best.Finish(Progress{LowerBound: lowerBound, Optimal: a.optimal})
+	if a.optimal {
+		return nil
+	}
+	if cancelled(ctx) {
+		return ctx.Err()
+	}
+
+	// members that refused the problem finish early, that is only an error if all of them did
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return errs[0]
+}
+
+func (a Portfolio) String() string {
+	names := make([]string, len(a.members))
+	for i, member := range a.members {
+		names[i] = member.String()
+	}
+	return fmt.Sprintf("Portfolio (%s)", strings.Join(names, ", "))
+}
+
+// runs several algorithms one after another. every stage after the first one starts from the
+// shortest cycle so far, if it supports a warm start. an initial cycle is the shortest cycle before
+// the first stage. only improvements are forwarded
+type Chain struct {
+	warmStart
+	optimal          bool
+	stages           []Algorithm
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+func NewChain(stages ...Algorithm) *Chain {
+	return &Chain{
+		stages:           stages,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+func (a *Chain) Optimal() bool {
+	return a.optimal
+}
+
+// passes the coordinates of the points to the stages that need them
+func (a *Chain) SetPoints(points []problem.Point) {
+	for _, stage := range a.stages {
+		if geometric, ok := stage.(Geometric); ok {
+			geometric.SetPoints(points)
+		}
+	}
+}
+
+// passes the channel for snapshots of the pheromones to the stages that keep pheromones
+func (a *Chain) SetPheromones(pheromones chan<- [][]float64) {
+	for _, stage := range a.stages {
+		if source, ok := stage.(PheromoneSource); ok {
+			source.SetPheromones(pheromones)
+		}
+	}
+}
+
+// passes a source of randomness, derived from the given one, to every stage that is stochastic
+func (a *Chain) SetRandom(random *rand.Rand) {
+	for _, stage := range a.stages {
+		if randomized, ok := stage.(Randomized); ok {
+			randomized.SetRandom(deriveRandom(random))
+		}
+	}
+}
+
+// supports asymmetric problems only if every stage does
+func (a *Chain) SupportsAsymmetric() bool {
+	for _, stage := range a.stages {
+		if !supportsAsymmetric(stage) {
+			return false
+		}
+	}
+	return true
+}
+
+// refuses the problem if any of the stages refuses it
+func (a *Chain) Validate(adjacency problem.Adjacency) error {
+	for _, stage := range a.stages {
+		if validator, ok := stage.(Validator); ok {
+			if err := validator.Validate(adjacency); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Chain) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	a.optimal = false
+	log.Printf("solving problemset with %d entries using %s", len(adjacency), a)
+
+	best := newIncumbent(updates)
+	if initial := a.validInitialCycle(adjacency); initial != nil {
+		best.Offer(initial, cycleDistance(adjacency, initial))
+	}
+
+	var lowerBound float64
+	for _, stage := range a.stages {
+		if cancelled(ctx) {
+			break
+		}
+
+		if cycle := best.Cycle(); cycle != nil {
+			if warmStarter, ok := stage.(WarmStarter); ok {
+				warmStarter.SetInitialCycle(cycle)
+			} else {
+				log.Printf("%s does not start from a given cycle, starting from scratch", stage)
+			}
+		}
+
+		stageUpdates := make(chan Progress, 10)
+		done := make(chan error, 1)
+		go func(stage Algorithm) {
+			done <- stage.Solve(ctx, adjacency, stageUpdates)
+		}(stage)
+		var final Progress
+		for progress := range stageUpdates {
+			forward(adjacency, best, stage, progress)
+			if progress.Final {
+				final = progress
+			}
+		}
+		lowerBound = math.Max(lowerBound, final.LowerBound)
+
+		// a stage that refuses the problem is skipped, an exact stage leaves nothing to improve
+		err := <-done
+		if err != nil && !cancelled(ctx) {
+			log.Printf("%s failed: %s", stage, err)
+		}
+		if err == nil && final.Optimal {
+			a.optimal = true
+			break
+		}
+	}
+
+	a.shortestDistance = best.Distance()
+	a.shortestCycle = best.Cycle()
+	best.Finish(Progress{LowerBound: lowerBound, Optimal: a.optimal})
+	return ctx.Err()
+}
+
+// forwards an event of a member of a portfolio or a stage of a chain. cycles are offered to the
+// incumbent, statistics are reported with the name of the member as phase. the final event of the
+// member is left to the caller
+func forward(adjacency problem.Adjacency, best *incumbent, member Algorithm, progress Progress) {
+	if progress.Cycle != nil {
+		best.Offer(progress.Cycle, cycleDistance(adjacency, progress.Cycle))
+		return
+	}
+	if progress.Final {
+		return
+	}
+
+	phase := member.String()
+	if progress.Phase != "" {
+		phase += ": " + progress.Phase
+	}
+	progress.Phase = phase
+	best.Report(progress)
+}
+
+func (a Chain) String() string {
+	names := make([]string, len(a.stages))
+	for i, stage := range a.stages {
+		names[i] = stage.String()
+	}
+	return strings.Join(names, " + ")
+}
@@ -0,0 +1,196 @@
+package algorithm
+
+import (
+	"context"
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPortfolio(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	a, err := FromString("nn+2opt,annealing,heldkarp")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, a, p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+	if !a.(Exact).Optimal() {
+		t.Fatalf("held-karp finished, the portfolio should be optimal")
+	}
+}
+
+func TestPortfolioStopsOnOptimality(t *testing.T) {
+	p := randomProblem(12, 11)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	// lin-kernighan without a limit of kicks only finishes when it is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	d := solveProblemContext(t, ctx, NewPortfolio(NewLinKernighan(), NewHeldKarp()), problem.NewProblem(p.Points))
+	if ctx.Err() != nil {
+		t.Fatalf("portfolio was not cancelled after held-karp finished")
+	}
+	if math.Abs(d-optimal) > epsilon {
+		t.Fatalf("distance %f is not the optimal distance %f", d, optimal)
+	}
+}
+
+func TestPortfolioFinalWithoutCycle(t *testing.T) {
+	refusing := func() Algorithm {
+		h := NewHeldKarp()
+		h.MemoryBudget = 0
+		return h
+	}
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a final event is sent even if no member or stage found a cycle
+	runs := map[string]struct {
+		ctx context.Context
+		a   Algorithm
+	}{
+		"refused":   {context.Background(), NewPortfolio(refusing(), refusing())},
+		"cancelled": {cancelledCtx, NewChain(NewNearestNeighbour(), NewTwoOpt())},
+	}
+	for name, run := range runs {
+		u := make(chan Progress, 10)
+		go run.a.Solve(run.ctx, randomProblem(10, 1).Adjacency, u)
+
+		var last Progress
+		for progress := range u {
+			last = progress
+		}
+		if !last.Final || last.Cycle != nil {
+			t.Fatalf("%s: expected a final event without a cycle, got %+v", name, last)
+		}
+	}
+}
+
+func TestChainWarmStart(t *testing.T) {
+	a, err := FromString("greedy+2opt:neighbours=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := a.(*Chain)
+	if len(chain.stages) != 2 || chain.String() != "Greedy Edge + 2-opt" {
+		t.Fatalf("wrong stages: %s", chain)
+	}
+
+	p := randomProblem(200, 12)
+	greedy := cycleDistance(p.Adjacency, GreedyEdge(p.Adjacency))
+	if d := solveProblem(t, a, p); d >= greedy {
+		t.Fatalf("distance %f is not shorter than greedy edge %f", d, greedy)
+	}
+
+	// the second stage started from the cycle of the first one
+	twoOpt := chain.stages[1].(*LocalSearch)
+	if cycleDistance(p.Adjacency, twoOpt.initialCycle) != greedy {
+		t.Fatalf("2-opt did not start from the cycle of greedy edge")
+	}
+}
+
+func TestPheromonesOfMembers(t *testing.T) {
+	p := randomProblem(20, 9)
+
+	// ant colonies that are stages of a chain or members of a portfolio send their pheromones
+	for _, spec := range []string{"nn+antcolony:iterations=10", "2opt,antcolony:iterations=10"} {
+		a, err := FromString(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source, ok := a.(PheromoneSource)
+		if !ok {
+			t.Fatalf("%s does not pass on the pheromones", spec)
+		}
+		pheromones := make(chan [][]float64, 10)
+		source.SetPheromones(pheromones)
+		solveProblem(t, a, p)
+		if len(pheromones) == 0 {
+			t.Fatalf("%s: no snapshot of the pheromones", spec)
+		}
+	}
+}
+
+func TestPortfolioFromString(t *testing.T) {
+	if _, err := FromString("nn+2opt,unknown"); err == nil {
+		t.Fatalf("expected error for unknown member")
+	}
+	if _, err := FromString("nn+2opt:unknown=1"); err == nil {
+		t.Fatalf("expected error for unknown parameter of a stage")
+	}
+}
+
+func TestRandomizedReproducible(t *testing.T) {
+	p := randomProblem(40, 15)
+	specs := []string{
+		"annealing:iterations=20000",
+		"antcolony:iterations=20",
+		"genetic:generations=50",
+		"ils:kicks=20",
+		"linkernighan:kicks=20",
+		"nn+annealing:iterations=20000",
+		"nn+2opt,annealing:iterations=20000,linkernighan:kicks=20",
+	}
+
+	// solves the problem with a source of randomness seeded with seed, returns the last cycle
+	solve := func(spec string, seed int64) problem.Cycle {
+		a, err := FromString(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.(Randomized).SetRandom(rand.New(rand.NewSource(seed)))
+
+		u := make(chan Progress, 10)
+		go a.Solve(context.Background(), p.Adjacency, u)
+		var cycle problem.Cycle
+		for progress := range u {
+			if progress.Cycle != nil {
+				cycle = progress.Cycle
+			}
+		}
+		return cycle
+	}
+
+	for _, spec := range specs {
+		if first, second := solve(spec, 5), solve(spec, 5); !reflect.DeepEqual(first, second) {
+			t.Fatalf("%s: runs with the same seed found different cycles: %v, %v", spec, first, second)
+		}
+	}
+
+	// the points of a grid have many shortest cycles, which the workers of the parallel bruteforce
+	// find in an order that depends on their timing
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	grid := make([]problem.Point, 0, 9)
+	for i := 0; i < 9; i++ {
+		grid = append(grid, problem.Point{X: float64(i % 3), Y: float64(i / 3)})
+	}
+	p = problem.NewProblemWithRandom(grid, rand.New(rand.NewSource(1)))
+	var first []int
+	for run := 0; run < 5; run++ {
+		a := NewBruteForce()
+		a.Parallel = true
+		solveProblem(t, a, p)
+		if first == nil {
+			first = a.shortestCycle
+		} else if !reflect.DeepEqual(first, a.shortestCycle) {
+			t.Fatalf("parallel bruteforce found different cycles: %v, %v", first, a.shortestCycle)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package algorithm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+const (
+	// default weight of the penalty for edges that were added often
+	DefaultDiversification = 0.3
+
+	// without a configured tenure, edges stay tabu for this fraction of the number of points
+	tabuTenureFactor = 0.25
+	tabuMinTenure    = 2
+	tabuMaxTenure    = 30
+
+	// without a configured limit, the search finishes after this factor times the number of
+	// points iterations without improvement
+	tabuStagnationFactor = 10
+)
+
+// improves a cycle by always applying the best 2-opt or swap move among the nearest neighbours,
+// even if it makes the cycle longer. the edges removed by a move are tabu for the next iterations,
+// a move that adds a tabu edge is only allowed if it leads to a cycle shorter than the shortest one
+// (aspiration). moves that do not improve the cycle are penalized by how often their edges were
+// added before (long-term memory), which drives the search into regions it has not visited yet
+type TabuSearch struct {
+	warmStart
+	Neighbours       int
+	Tenure           int
+	Diversification  float64
+	Iterations       int
+	Stagnation       int
+	shortestDistance float64
+	shortestCycle    problem.Cycle
+}
+
+// a 2-opt move reverses the path from p to q, a swap exchanges p and q. removed and added hold the
+// endpoints of the edges that are replaced, two consecutive points form an edge
+type tabuMove struct {
+	swap    bool
+	p, q    int
+	delta   float64
+	edges   int
+	removed [8]int
+	added   [8]int
+}
+
+func NewTabuSearch() *TabuSearch {
+	return &TabuSearch{
+		Neighbours:       DefaultNeighbours,
+		Diversification:  DefaultDiversification,
+		shortestDistance: math.MaxFloat64,
+	}
+}
+
+// sets the parameters of tabu search, supported keys are:
+//   - neighbours: number of nearest neighbours considered for a move
+//   - tenure: number of iterations a removed edge stays tabu, 0 to derive it from the problem
+//   - diversification: weight of the penalty for frequently added edges, 0 to disable it
+//   - iterations: number of iterations after which the algorithm finishes, 0 for no limit
+//   - stagnation: number of iterations without improvement after which the algorithm finishes,
+//     0 to derive it from the problem
+func (a *TabuSearch) Configure(key, value string) error {
+	switch key {
+	case "neighbours":
+		neighbours, err := strconv.Atoi(value)
+		if err != nil || neighbours < 1 {
+			return fmt.Errorf("invalid number of neighbours: %s", value)
+		}
+		a.Neighbours = neighbours
+	case "tenure":
+		tenure, err := strconv.Atoi(value)
+		if err != nil || tenure < 0 {
+			return fmt.Errorf("invalid tabu tenure: %s", value)
+		}
+		a.Tenure = tenure
+	case "diversification":
+		diversification, err := strconv.ParseFloat(value, 64)
+		if err != nil || diversification < 0 {
+			return fmt.Errorf("invalid diversification: %s", value)
+		}
+		a.Diversification = diversification
+	case "iterations":
+		iterations, err := strconv.Atoi(value)
+		if err != nil || iterations < 0 {
+			return fmt.Errorf("invalid number of iterations: %s", value)
+		}
+		a.Iterations = iterations
+	case "stagnation":
+		stagnation, err := strconv.Atoi(value)
+		if err != nil || stagnation < 0 {
+			return fmt.Errorf("invalid number of iterations without improvement: %s", value)
+		}
+		a.Stagnation = stagnation
+	default:
+		return fmt.Errorf("unknown parameter for %s: %s", a, key)
+	}
+	return nil
+}
+
+func (a *TabuSearch) Solve(ctx context.Context, adjacency problem.Adjacency, updates chan Progress) error {
+	defer close(updates)
+	n := len(adjacency)
+
+	tenure := a.Tenure
+	if tenure == 0 {
+		tenure = int(math.Max(tabuMinTenure, math.Min(tabuMaxTenure, tabuTenureFactor*float64(n))))
+	}
+	stagnation := a.Stagnation
+	if stagnation == 0 {
+		stagnation = tabuStagnationFactor * n
+	}
+	log.Printf("solving problemset with %d entries using tabu search, tenure %d, diversification %.2f",
+		n, tenure, a.Diversification)
+
+	if n > 0 {
+		throttled := newThrottledUpdates(updates)
+		t := newTour(a.startCycle(adjacency))
+		distance := t.distance(adjacency)
+		a.shortestDistance = distance
+		a.shortestCycle = t.Cycle()
+		throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance})
+
+		neighbours := nearestNeighbours(adjacency, a.Neighbours)
+		averageEdge := distance / float64(n)
+
+		// iteration until which an edge is tabu and how often it was added, by edge key
+		tabu := make(map[int]int)
+		frequency := make(map[int]int)
+		key := func(p, q int) int {
+			if p > q {
+				p, q = q, p
+			}
+			return p*n + q
+		}
+
+		moves := make([]tabuMove, 0, 4)
+		sinceImprovement := 0
+		iteration := 0
+		for n >= 4 && !cancelled(ctx) && (a.Iterations == 0 || iteration < a.Iterations) {
+			iteration++
+			throttled.Report(Progress{Iteration: iteration, Stats: map[string]float64{"since improvement": float64(sinceImprovement)}})
+
+			var best tabuMove
+			var bestScore float64
+			found := false
+			for x := range adjacency {
+				for _, c := range neighbours[x] {
+					moves = tabuMoves(adjacency, t, x, c, moves[:0])
+					for m := range moves {
+						move := &moves[m]
+
+						// the penalty is never negative, skip moves that can not be better before
+						// looking at the memory
+						if found && move.delta >= bestScore {
+							continue
+						}
+						if !a.admissible(move, iteration, distance, tabu, key) {
+							continue
+						}
+
+						score := move.delta
+						if move.delta >= 0 && a.Diversification > 0 {
+							var added int
+							for k := 0; k < 2*move.edges; k += 2 {
+								added += frequency[key(move.added[k], move.added[k+1])]
+							}
+							score += a.Diversification * averageEdge * float64(added) / float64(iteration)
+						}
+
+						if !found || score < bestScore {
+							best, bestScore, found = *move, score, true
+						}
+					}
+				}
+			}
+
+			// every move is tabu, wait for the tabu list to expire
+			if !found {
+				continue
+			}
+
+			if best.swap {
+				t.swap(best.p, best.q)
+			} else {
+				t.reverse(best.p, best.q)
+			}
+			distance += best.delta
+			for k := 0; k < 2*best.edges; k += 2 {
+				tabu[key(best.removed[k], best.removed[k+1])] = iteration + tenure
+			}
+			for k := 0; k < 2*best.edges; k += 2 {
+				frequency[key(best.added[k], best.added[k+1])]++
+			}
+
+			// get rid of rounding errors that accumulated in the distance
+			if iteration%n == 0 {
+				distance = t.distance(adjacency)
+			}
+
+			if distance < a.shortestDistance-epsilon {
+				a.shortestDistance = distance
+				a.shortestCycle = t.Cycle()
+				throttled.Send(Progress{Cycle: a.shortestCycle, Distance: distance, Iteration: iteration})
+				sinceImprovement = 0
+			} else if sinceImprovement++; sinceImprovement >= stagnation {
+				break
+			}
+		}
+
+		throttled.Finish(Progress{Iteration: iteration})
+	}
+
+	return ctx.Err()
+}
+
+func (a TabuSearch) String() string {
+	return "Tabu Search"
+}
+
+// tests if a move adds no tabu edge or leads to a cycle shorter than the shortest one
+func (a *TabuSearch) admissible(move *tabuMove, iteration int, distance float64, tabu map[int]int, key func(p, q int) int) bool {
+	if distance+move.delta < a.shortestDistance-epsilon {
+		return true
+	}
+	for k := 0; k < 2*move.edges; k += 2 {
+		if tabu[key(move.added[k], move.added[k+1])] >= iteration {
+			return false
+		}
+	}
+	return true
+}
+
+// appends the moves that add the edge (x, c) to moves: the two 2-opt moves and the swaps of x
+// with the points next to c
+func tabuMoves(adjacency problem.Adjacency, t *tour, x, c int, moves []tabuMove) []tabuMove {
+	// x and its successor b, replace (x, b) and (c, d) by (x, c) and (b, d)
+	if b, d := t.next(x), t.next(c); c != b && d != x {
+		moves = append(moves, tabuMove{
+			p:       b,
+			q:       c,
+			delta:   adjacency[x][c] + adjacency[b][d] - adjacency[x][b] - adjacency[c][d],
+			edges:   2,
+			removed: [8]int{x, b, c, d},
+			added:   [8]int{x, c, b, d},
+		})
+	}
+
+	// x and its predecessor b, replace (b, x) and (d, c) by (c, x) and (d, b)
+	if b, d := t.prev(x), t.prev(c); c != b && d != x {
+		moves = append(moves, tabuMove{
+			p:       x,
+			q:       d,
+			delta:   adjacency[x][c] + adjacency[b][d] - adjacency[b][x] - adjacency[d][c],
+			edges:   2,
+			removed: [8]int{b, x, d, c},
+			added:   [8]int{c, x, d, b},
+		})
+	}
+
+	// move x next to c by swapping it with one of the neighbours of c
+	for _, y := range [2]int{t.prev(c), t.next(c)} {
+		px, nx, py, ny := t.prev(x), t.next(x), t.prev(y), t.next(y)
+		if y == x || nx == y || ny == x {
+			continue
+		}
+		moves = append(moves, tabuMove{
+			swap:    true,
+			p:       x,
+			q:       y,
+			delta:   adjacency[px][y] + adjacency[y][nx] + adjacency[py][x] + adjacency[x][ny] - adjacency[px][x] - adjacency[x][nx] - adjacency[py][y] - adjacency[y][ny],
+			edges:   4,
+			removed: [8]int{px, x, x, nx, py, y, y, ny},
+			added:   [8]int{px, y, y, nx, py, x, x, ny},
+		})
+	}
+
+	return moves
+}
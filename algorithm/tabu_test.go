@@ -0,0 +1,60 @@
+package algorithm
+
+import (
+	"leistungsnachweis-graphiker/problem"
+	"math"
+	"testing"
+)
+
+func TestTabuSearch(t *testing.T) {
+	points := []problem.Point{
+		{X: 0, Y: 0},
+		{X: 50, Y: 0},
+		{X: 50, Y: 50},
+		{X: 0, Y: 50},
+		{X: 25, Y: 75},
+	}
+
+	p := problem.NewProblem(points)
+	if d := solveProblem(t, NewTabuSearch(), p); math.Round(d*100)/100 != 220.71 {
+		t.Fatalf("wrong distance: %f", d)
+	}
+}
+
+func TestTabuSearchOptimal(t *testing.T) {
+	p := randomProblem(14, 6)
+	optimal := solveProblem(t, NewHeldKarp(), p)
+
+	if d := solveProblem(t, NewTabuSearch(), p); d > 1.02*optimal {
+		t.Fatalf("distance %f is not within 2%% of optimal distance %f", d, optimal)
+	}
+}
+
+func TestTabuSearchEscapesLocalOptimum(t *testing.T) {
+	p := randomProblem(300, 7)
+	local := NewTwoOpt()
+	twoOpt := solveProblem(t, local, p)
+
+	// starting at the local optimum of 2-opt, the search has to continue past it to improve
+	a := NewTabuSearch()
+	a.SetInitialCycle(local.shortestCycle)
+	if d := solveProblem(t, a, p); d >= twoOpt {
+		t.Fatalf("distance %f is not shorter than 2-opt %f", d, twoOpt)
+	}
+}
+
+func TestTabuSearchConfigure(t *testing.T) {
+	a, err := FromString("tabu:tenure=7:diversification=0:iterations=100")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tabu := a.(*TabuSearch)
+	if tabu.Tenure != 7 || tabu.Diversification != 0 || tabu.Iterations != 100 {
+		t.Fatalf("parameters not applied: %+v", tabu)
+	}
+
+	if _, err := FromString("tabu:tenure=-1"); err == nil {
+		t.Fatalf("expected error for negative tenure")
+	}
+}
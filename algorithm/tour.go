@@ -0,0 +1,242 @@
+package algorithm
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// a cycle that knows the position of each of its points, so that successors and predecessors
+// can be looked up in constant time. used by the local search algorithms to apply moves
+type tour struct {
+	cycle     problem.Cycle
+	positions []int
+}
+
+func newTour(cycle problem.Cycle) *tour {
+	t := &tour{
+		cycle:     make(problem.Cycle, len(cycle)),
+		positions: make([]int, len(cycle)),
+	}
+	copy(t.cycle, cycle)
+	for i, p := range t.cycle {
+		t.positions[p] = i
+	}
+	return t
+}
+
+// the initial cycle of an algorithm that implements WarmStarter, embedded by the algorithms
+type warmStart struct {
+	initialCycle problem.Cycle
+}
+
+// sets the cycle the algorithm starts from
+func (w *warmStart) SetInitialCycle(cycle problem.Cycle) {
+	w.initialCycle = cycle
+}
+
+// returns a copy of the initial cycle if it visits every point of the problem exactly once,
+// otherwise the cycle of nearest neighbour
+func (w *warmStart) startCycle(adjacency problem.Adjacency) problem.Cycle {
+	if cycle := w.validInitialCycle(adjacency); cycle != nil {
+		return cycle
+	}
+	return NearestNeighbour(adjacency, 0)
+}
+
+// returns a copy of the initial cycle if it visits every point of the problem exactly once,
+// otherwise nil
+func (w *warmStart) validInitialCycle(adjacency problem.Adjacency) problem.Cycle {
+	n := len(adjacency)
+	if w.initialCycle == nil {
+		return nil
+	}
+	if !isPermutation(w.initialCycle, n) {
+		log.Printf("initial cycle does not fit the problem, ignoring it")
+		return nil
+	}
+
+	cycle := make(problem.Cycle, n)
+	copy(cycle, w.initialCycle)
+	return cycle
+}
+
+// tells if a cycle visits each of the n points exactly once
+func isPermutation(cycle []int, n int) bool {
+	if len(cycle) != n {
+		return false
+	}
+	visited := make([]bool, n)
+	for _, p := range cycle {
+		if p < 0 || p >= n || visited[p] {
+			return false
+		}
+		visited[p] = true
+	}
+	return true
+}
+
+// returns the point that follows p
+func (t *tour) next(p int) int {
+	i := t.positions[p] + 1
+	if i == len(t.cycle) {
+		i = 0
+	}
+	return t.cycle[i]
+}
+
+// returns the point that precedes p
+func (t *tour) prev(p int) int {
+	i := t.positions[p] - 1
+	if i < 0 {
+		i = len(t.cycle) - 1
+	}
+	return t.cycle[i]
+}
+
+// reverses the path that goes from point from to point to in forward direction. if the path is
+// longer than half of the cycle, the rest of the cycle is reversed instead, which results in the
+// same cycle, just traversed in the other direction
+func (t *tour) reverse(from, to int) {
+	n := len(t.cycle)
+	i, j := t.positions[from], t.positions[to]
+	length := (j-i+n)%n + 1
+	if 2*length > n {
+		i, j = (j+1)%n, (i-1+n)%n
+		length = n - length
+	}
+
+	for k := 0; k < length/2; k++ {
+		t.cycle[i], t.cycle[j] = t.cycle[j], t.cycle[i]
+		t.positions[t.cycle[i]] = i
+		t.positions[t.cycle[j]] = j
+		if i++; i == n {
+			i = 0
+		}
+		if j--; j < 0 {
+			j = n - 1
+		}
+	}
+}
+
+// moves the path that goes from point first to point last in forward direction, so that it
+// follows point after. if reversed is true, the path is inserted in opposite direction
+func (t *tour) move(first, last, after int, reversed bool) {
+	n := len(t.cycle)
+	segment := make([]int, 0, n)
+	for p := first; ; p = t.next(p) {
+		segment = append(segment, p)
+		if p == last {
+			break
+		}
+	}
+	if reversed {
+		for l, r := 0, len(segment)-1; l < r; l, r = l+1, r-1 {
+			segment[l], segment[r] = segment[r], segment[l]
+		}
+	}
+
+	// rebuild the cycle, starting with the point after the segment
+	cycle := make(problem.Cycle, 0, n)
+	for p := t.next(last); p != first; p = t.next(p) {
+		cycle = append(cycle, p)
+		if p == after {
+			cycle = append(cycle, segment...)
+		}
+	}
+
+	t.cycle = cycle
+	for i, p := range t.cycle {
+		t.positions[p] = i
+	}
+}
+
+// cuts the cycle at three random positions that are at most maxSegment positions apart from a
+// random start and reconnects the four parts a b c d as a c b d. returns the endpoints of the
+// changed edges
+func (t *tour) doubleBridge(random *rand.Rand, maxSegment int) []int {
+	n := len(t.cycle)
+	if maxSegment > n-1 {
+		maxSegment = n - 1
+	}
+
+	// rotate the cycle so that it starts at a random position, the cuts are at 0 < i < j < k
+	start := random.Intn(n)
+	cuts := random.Perm(maxSegment)[:3]
+	i, j, k := cuts[0]+1, cuts[1]+1, cuts[2]+1
+	if i > j {
+		i, j = j, i
+	}
+	if j > k {
+		j, k = k, j
+	}
+	if i > j {
+		i, j = j, i
+	}
+
+	rotated := make(problem.Cycle, n)
+	for p := range rotated {
+		rotated[p] = t.cycle[(start+p)%n]
+	}
+
+	cycle := make(problem.Cycle, 0, n)
+	cycle = append(cycle, rotated[:i]...)
+	cycle = append(cycle, rotated[j:k]...)
+	cycle = append(cycle, rotated[i:j]...)
+	cycle = append(cycle, rotated[k:]...)
+
+	t.cycle = cycle
+	for p, q := range t.cycle {
+		t.positions[q] = p
+	}
+
+	return []int{rotated[i-1], rotated[i], rotated[j-1], rotated[j], rotated[k-1], rotated[k]}
+}
+
+// returns the distance of the cycle
+func (t *tour) distance(adjacency problem.Adjacency) float64 {
+	return cycleDistance(adjacency, t.cycle)
+}
+
+// returns a copy of the cycle
+func (t *tour) Cycle() problem.Cycle {
+	cycle := make(problem.Cycle, len(t.cycle))
+	copy(cycle, t.cycle)
+	return cycle
+}
+
+// calculates the k nearest neighbours of every point, ordered by ascending distance
+func nearestNeighbours(adjacency problem.Adjacency, k int) [][]int {
+	n := len(adjacency)
+	if k > n-1 {
+		k = n - 1
+	}
+	if k < 0 {
+		k = 0
+	}
+
+	neighbours := make([][]int, n)
+	candidates := make([]int, 0, n)
+	for i := range adjacency {
+		candidates = candidates[:0]
+		for j := range adjacency {
+			if i != j {
+				candidates = append(candidates, j)
+			}
+		}
+		sort.Slice(candidates, func(x, y int) bool {
+			return adjacency[i][candidates[x]] < adjacency[i][candidates[y]]
+		})
+		neighbours[i] = append([]int(nil), candidates[:k]...)
+	}
+	return neighbours
+}
+
+// exchanges the positions of the points p and q
+func (t *tour) swap(p, q int) {
+	i, j := t.positions[p], t.positions[q]
+	t.cycle[i], t.cycle[j] = q, p
+	t.positions[p], t.positions[q] = j, i
+}
@@ -18,16 +18,57 @@ func main() {
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:  "algorithm",
-			Usage: "name of the algorithm to use",
+			Usage: "name of the algorithm to use, e.g. \"heldkarp\" or the portfolio \"nn+2opt,heldkarp\"",
 		},
 		cli.StringFlag{
 			Name:  "problem",
-			Usage: "path to the problem-file to be solved",
+			Usage: "path to the problem-file to be solved, a json file or a tsplib .tsp or .atsp file",
+		},
+		cli.StringFlag{
+			Name:  "initial-tour",
+			Usage: "path to a tour to start from, a json list of point names or indices or a tsplib .tour",
 		},
 		cli.StringFlag{
 			Name:  "bind",
 			Usage: "address to listen for websocket-connections",
 		},
+		cli.DurationFlag{
+			Name:  "time-limit",
+			Usage: "stop after this wall-clock time, e.g. \"30s\" or \"5m\"",
+		},
+		cli.IntFlag{
+			Name:  "iterations",
+			Usage: "stop after the algorithm reported this many iterations",
+		},
+		cli.Float64Flag{
+			Name:  "gap",
+			Usage: "stop when the shortest cycle is within this many percent of the lower bound",
+		},
+		cli.Float64Flag{
+			Name:  "target",
+			Usage: "stop as soon as a cycle is at least as short as this distance",
+		},
+		cli.DurationFlag{
+			Name:  "no-improvement",
+			Usage: "stop when the shortest cycle did not improve for this long, e.g. \"10s\"",
+		},
+		cli.Int64Flag{
+			Name:  "seed",
+			Usage: "seed of the random number generator, runs with the same seed and algorithm find the same tours unless stopped by a time",
+		},
+		cli.StringFlag{
+			Name:  "checkpoint",
+			Usage: "path to write checkpoints to, which allow to resume the run",
+		},
+		cli.DurationFlag{
+			Name:  "checkpoint-interval",
+			Usage: "time between two checkpoints",
+			Value: time.Minute,
+		},
+		cli.StringFlag{
+			Name:  "resume",
+			Usage: "path to a checkpoint to resume, the algorithm of the checkpoint is used if none is given",
+		},
 	}
 	app.Action = startCli
 
@@ -40,8 +81,21 @@ func main() {
 func startCli(c *cli.Context) {
 	algorithm := c.String("algorithm")
 	problem := c.String("problem")
+	initialTour := c.String("initial-tour")
 	bind := c.String("bind")
-	cliController := solver.NewCli(algorithm, problem, bind)
+	criteria := solver.StoppingCriteria{
+		TimeLimit:     c.Duration("time-limit"),
+		Iterations:    c.Int("iterations"),
+		Gap:           c.Float64("gap"),
+		Target:        c.Float64("target"),
+		NoImprovement: c.Duration("no-improvement"),
+	}
+	checkpoints := solver.CheckpointConfig{
+		Path:     c.String("checkpoint"),
+		Interval: c.Duration("checkpoint-interval"),
+		Resume:   c.String("resume"),
+	}
+	cliController := solver.NewCli(algorithm, problem, initialTour, bind, criteria, checkpoints, c.Int64("seed"))
 	time.Sleep(time.Second * 3)
 	cliController.Start()
 }
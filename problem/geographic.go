@@ -0,0 +1,143 @@
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// axis conventions of geographic problems whose points are given as x and y instead of lat and lon
+const (
+	// x is the longitude and y the latitude, like on a map and on the image of the problem
+	AxesLonLat = "lonlat"
+
+	// x is the latitude and y the longitude
+	AxesLatLon = "latlon"
+)
+
+// reads a point that is given either as x and y or, for geographic problems, as lat and lon.
+// points given as lat and lon are stored with the longitude as x and the latitude as y
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		X    *float64 `json:"x"`
+		Y    *float64 `json:"y"`
+		Lat  *float64 `json:"lat"`
+		Lon  *float64 `json:"lon"`
+		Name string   `json:"name"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.Name = raw.Name
+	switch {
+	case raw.Lat != nil && raw.Lon != nil:
+		if raw.X != nil || raw.Y != nil {
+			return fmt.Errorf("point \"%s\" is given as both x/y and lat/lon", raw.Name)
+		}
+		p.X, p.Y, p.latLon = *raw.Lon, *raw.Lat, true
+	case raw.Lat != nil || raw.Lon != nil:
+		return fmt.Errorf("point \"%s\" needs both lat and lon", raw.Name)
+	default:
+		if raw.X != nil {
+			p.X = *raw.X
+		}
+		if raw.Y != nil {
+			p.Y = *raw.Y
+		}
+	}
+	return nil
+}
+
+// tells if the points are given as longitude and latitude, which depends on the metric
+func (p *Problem) geographic() bool {
+	metric, err := lookupMetric(p.Info.Type)
+	return err == nil && metric.Geographic
+}
+
+// brings the points of a geographic problem to the convention of x as longitude and y as latitude
+// and validates their ranges. points given as x and y follow the declared axes, files that do not
+// declare their axes are read as AxesLonLat with a warning, as older versions read them the other way
+func (p *Problem) normalizeCoordinates() error {
+	if !p.geographic() {
+		return nil
+	}
+
+	axes := strings.ToLower(p.Info.Axes)
+	if axes != "" && axes != AxesLonLat && axes != AxesLatLon {
+		return fmt.Errorf("unknown axes %s, expected %s or %s", p.Info.Axes, AxesLonLat, AxesLatLon)
+	}
+
+	undeclared := false
+	for i := range p.Points {
+		point := &p.Points[i]
+		if point.latLon {
+			continue
+		}
+		undeclared = undeclared || axes == ""
+		if axes == AxesLatLon {
+			point.X, point.Y = point.Y, point.X
+		}
+	}
+	if undeclared {
+		log.Printf("problem \"%s\" does not declare its axes, reading x as longitude and y as latitude. "+
+			"use lat and lon or declare \"axes\" to silence this warning", p.Info.Name)
+	}
+
+	swapped := AxesLatLon
+	if axes == AxesLatLon {
+		swapped = AxesLonLat
+	}
+	for i, point := range p.Points {
+		if validCoordinates(point.Y, point.X) {
+			continue
+		}
+
+		// points are not shuffled yet, i is the position in the file
+		name := point.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i)
+		}
+		if !point.latLon && validCoordinates(point.X, point.Y) {
+			return fmt.Errorf("point %s has latitude %v, the axes look swapped, declare \"axes\": \"%s\"",
+				name, point.Y, swapped)
+		}
+		return fmt.Errorf("point %s has latitude %v and longitude %v, expected [-90, 90] and [-180, 180]",
+			name, point.Y, point.X)
+	}
+
+	// points whose coordinates are both within [-90, 90] are valid either way, but the bounds of the
+	// image tell which way they belong, unless they are given as lat and lon. the axes look swapped
+	// if most of the points are only on the image with x and y swapped
+	if !p.Image.bounded() {
+		return nil
+	}
+	swappedInside := 0
+	for _, point := range p.Points {
+		if !point.latLon && !p.Image.contains(point.X, point.Y) && p.Image.contains(point.Y, point.X) {
+			swappedInside++
+		}
+	}
+	if 2*swappedInside > len(p.Points) {
+		log.Printf("%d of %d points of problem \"%s\" are only on the image with x and y swapped, "+
+			"the axes look swapped, declare \"axes\": \"%s\"", swappedInside, len(p.Points), p.Info.Name, swapped)
+	}
+	return nil
+}
+
+// tells if the image declares the coordinates of its corners
+func (i Image) bounded() bool {
+	return i.X1 != i.X2 && i.Y1 != i.Y2
+}
+
+// tells if a point lies on the image, whose corners may be given in any order
+func (i Image) contains(x, y float64) bool {
+	return x >= math.Min(i.X1, i.X2) && x <= math.Max(i.X1, i.X2) && y >= math.Min(i.Y1, i.Y2) && y <= math.Max(i.Y1, i.Y2)
+}
+
+// tells if a latitude and a longitude are in range
+func validCoordinates(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
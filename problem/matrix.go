@@ -0,0 +1,123 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// shapes of the matrices that problem files may give instead of calculating the distances from the
+// coordinates, indices into matrixShapes
+const (
+	fullMatrix = iota
+	lowerDiagonalRows
+	lowerRows
+	upperDiagonalRows
+	upperRows
+)
+
+// length is the number of entries in row i and column the column of the k-th entry
+var matrixShapes = []struct {
+	name   string
+	length func(i, n int) int
+	column func(i, k int) int
+}{
+	{"full", func(i, n int) int { return n }, func(i, k int) int { return k }},
+	{"lower triangular", func(i, n int) int { return i + 1 }, func(i, k int) int { return k }},
+	{"lower triangular without diagonal", func(i, n int) int { return i }, func(i, k int) int { return k }},
+	{"upper triangular", func(i, n int) int { return n - i }, func(i, k int) int { return i + k }},
+	{"upper triangular without diagonal", func(i, n int) int { return n - i - 1 }, func(i, k int) int { return i + 1 + k }},
+}
+
+// reads the distances of a problem file that gives them as "adjacency" or "weights" instead of
+// calculating them from the coordinates of the points
+func (p *Problem) readWeights(bytes []byte) error {
+	var file struct {
+		Weights Adjacency `json:"weights"`
+	}
+	if err := json.Unmarshal(bytes, &file); err != nil {
+		return err
+	}
+
+	rows := p.Adjacency
+	if file.Weights != nil {
+		if rows != nil {
+			return errors.New("problem gives both adjacency and weights")
+		}
+		rows = file.Weights
+	}
+	if rows == nil {
+		if p.Info.Asymmetric {
+			return errors.New("asymmetric problems need a matrix of distances")
+		}
+		return nil
+	}
+
+	weights, err := expandMatrix(rows, len(p.Points), p.Info.Asymmetric)
+	if err != nil {
+		return err
+	}
+	p.weights = weights
+	return nil
+}
+
+// expands a full or triangular matrix of the distances between n points to a full matrix. the
+// entries of triangular matrices are mirrored, full matrices have to be symmetric unless the
+// problem is asymmetric, which needs a full matrix
+func expandMatrix(rows Adjacency, n int, asymmetric bool) (Adjacency, error) {
+	if len(rows) != n {
+		return nil, fmt.Errorf("matrix has %d rows, the problem has %d points", len(rows), n)
+	}
+
+	shape := -1
+	for s := range matrixShapes {
+		fits := true
+		for i, row := range rows {
+			fits = fits && len(row) == matrixShapes[s].length(i, n)
+		}
+		if fits {
+			shape = s
+			break
+		}
+	}
+	if shape < 0 {
+		return nil, errors.New("matrix is neither full nor triangular")
+	}
+	if asymmetric && shape != fullMatrix {
+		return nil, errors.New("asymmetric problems need a full matrix")
+	}
+
+	full := make(Adjacency, n)
+	for i := range full {
+		full[i] = make([]float64, n)
+	}
+	for i, row := range rows {
+		for k, distance := range row {
+			j := matrixShapes[shape].column(i, k)
+			if math.IsNaN(distance) || math.IsInf(distance, 0) || distance < 0 {
+				return nil, fmt.Errorf("distance between %d and %d is %v, expected a non-negative number", i, j, distance)
+			}
+			full[i][j] = distance
+			if shape != fullMatrix {
+				full[j][i] = distance
+			}
+		}
+	}
+
+	for i := range full {
+		for j := range full {
+			if !asymmetric && full[i][j] != full[j][i] {
+				return nil, fmt.Errorf("matrix is not symmetric, distance between %d and %d is %v and %v, "+
+					"declare \"asymmetric\": true if the distances depend on the direction", i, j, full[i][j], full[j][i])
+			}
+		}
+	}
+	return full, nil
+}
+
+// tells if the distances are given by the problem file instead of calculated from the coordinates
+// of the points, whose coordinates then only serve the visualization
+func (p *Problem) Explicit() bool {
+	return p.weights != nil
+}
@@ -0,0 +1,116 @@
+package problem
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProblemMatrix(t *testing.T) {
+	expected := Adjacency{
+		{0, 4, 7, 3},
+		{4, 0, 2, 5},
+		{7, 2, 0, 6},
+		{3, 5, 6, 0},
+	}
+	points := `"points": [{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}]`
+	problems := map[string]string{
+		"full.json":       `{` + points + `, "adjacency": [[0, 4, 7, 3], [4, 0, 2, 5], [7, 2, 0, 6], [3, 5, 6, 0]]}`,
+		"weights.json":    `{` + points + `, "weights": [[0, 4, 7, 3], [4, 0, 2, 5], [7, 2, 0, 6], [3, 5, 6, 0]]}`,
+		"lower.json":      `{` + points + `, "weights": [[0], [4, 0], [7, 2, 0], [3, 5, 6, 0]]}`,
+		"lowerplain.json": `{` + points + `, "weights": [[], [4], [7, 2], [3, 5, 6]]}`,
+		"upper.json":      `{` + points + `, "weights": [[0, 4, 7, 3], [0, 2, 5], [0, 6], [0]]}`,
+		"upperplain.json": `{` + points + `, "weights": [[4, 7, 3], [2, 5], [6], []]}`,
+		"geographic.json": `{"info": {"type": "geographic"}, ` + points + `, "weights": [[4, 7, 3], [2, 5], [6], []]}`,
+	}
+
+	for name, content := range problems {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		problem, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if !problem.Explicit() {
+			t.Fatalf("%s: distances are not taken from the file", name)
+		}
+
+		// the distances follow the shuffled points, also after arranging them differently
+		for round := 0; round < 2; round++ {
+			order := problem.Order()
+			for i := range order {
+				for j := range order {
+					if d := problem.Adjacency[i][j]; d != expected[order[i]][order[j]] {
+						t.Fatalf("%s: distance between %s and %s is %f, expected %f", name,
+							problem.Points[i].Name, problem.Points[j].Name, d, expected[order[i]][order[j]])
+					}
+				}
+			}
+			if err := problem.SetOrder([]int{3, 1, 0, 2}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	invalid := map[string]string{
+		"rows.json":      `{` + points + `, "weights": [[4, 7, 3], [2, 5], [6]]}`,
+		"ragged.json":    `{` + points + `, "weights": [[0, 4, 7, 3], [4, 0, 2], [7, 2, 0, 6], [3, 5, 6, 0]]}`,
+		"negative.json":  `{` + points + `, "weights": [[4, -7, 3], [2, 5], [6], []]}`,
+		"symmetric.json": `{` + points + `, "weights": [[0, 4, 7, 3], [4, 0, 2, 5], [7, 2, 0, 6], [3, 5, 1, 0]]}`,
+		"both.json":      `{` + points + `, "adjacency": [[4, 7, 3], [2, 5], [6], []], "weights": [[4, 7, 3], [2, 5], [6], []]}`,
+	}
+	for name, content := range invalid {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if _, err := FromFile(path); err == nil {
+			t.Fatalf("%s: expected error for invalid matrix", name)
+		}
+	}
+
+	if _, err := expandMatrix(Adjacency{{}, {math.NaN()}}, 2, false); err == nil {
+		t.Fatalf("expected error for a distance that is not a number")
+	}
+}
+
+func TestProblemMatrixAsymmetric(t *testing.T) {
+	expected := Adjacency{
+		{0, 1, 9},
+		{9, 0, 1},
+		{1, 9, 0},
+	}
+	points := `"points": [{"name": "a"}, {"name": "b"}, {"name": "c"}]`
+	path := writeFile(t, "asymmetric.json", `{"info": {"asymmetric": true}, `+points+`, "weights": [[0, 1, 9], [9, 0, 1], [1, 9, 0]]}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	problem, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problem.Adjacency.Symmetric() {
+		t.Fatalf("distances of an asymmetric problem are symmetric")
+	}
+	order := problem.Order()
+	for i := range order {
+		for j := range order {
+			if d := problem.Adjacency[i][j]; d != expected[order[i]][order[j]] {
+				t.Fatalf("distance from %d to %d is %f, expected %f", order[i], order[j], d, expected[order[i]][order[j]])
+			}
+		}
+	}
+
+	invalid := map[string]string{
+		"triangular.json": `{"info": {"asymmetric": true}, ` + points + `, "weights": [[1, 9], [1], []]}`,
+		"points.json":     `{"info": {"asymmetric": true}, ` + points + `}`,
+	}
+	for name, content := range invalid {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if _, err := FromFile(path); err == nil {
+			t.Fatalf("%s: expected error for invalid asymmetric problem", name)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package problem
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// further types of problems, each type selects the metric that calculates the distances
+const (
+	// sum of the differences of the coordinates, tsplib MAN_2D
+	Manhattan = "manhattan"
+
+	// maximum of the differences of the coordinates, tsplib MAX_2D
+	Chebyshev = "chebyshev"
+
+	// euclidean distance rounded to the nearest integer, tsplib EUC_2D
+	RoundedEuclidean = "euclidean-rounded"
+
+	// euclidean distance rounded up, tsplib CEIL_2D
+	CeilEuclidean = "euclidean-ceil"
+
+	// pseudo-euclidean distance of the att problems of tsplib, ATT
+	ATT = "att"
+
+	// great-circle distance of tsplib, GEO. coordinates are given as degrees and minutes, e.g.
+	// 38.24 for 38°24', and distances are truncated to kilometers
+	TSPLIBGeo = "tsplib-geo"
+
+	// distance on the wgs-84 ellipsoid in kilometers, more accurate than the haversine formula
+	Vincenty = "vincenty"
+)
+
+// calculates the distance between two points of a problem
+type Metric struct {
+	Distance func(p1, p2 Point) float64
+
+	// the points are given as longitude and latitude, see AxesLonLat
+	Geographic bool
+}
+
+// metrics by the lower-case type of the problem, including the names of the tsplib edge weight types
+var metrics = map[string]Metric{
+	Euclidean:        {Distance: euclidean},
+	Geographic:       {Distance: haversine, Geographic: true},
+	Manhattan:        {Distance: manhattan},
+	Chebyshev:        {Distance: chebyshev},
+	RoundedEuclidean: {Distance: roundedEuclidean},
+	CeilEuclidean:    {Distance: ceilEuclidean},
+	ATT:              {Distance: att},
+	TSPLIBGeo:        {Distance: tsplibGeo, Geographic: true},
+	Vincenty:         {Distance: vincenty, Geographic: true},
+	"euc_2d":         {Distance: roundedEuclidean},
+	"ceil_2d":        {Distance: ceilEuclidean},
+	"man_2d":         {Distance: manhattan},
+	"max_2d":         {Distance: chebyshev},
+	"geo":            {Distance: tsplibGeo, Geographic: true},
+}
+
+// adds a metric that problems select by their type, e.g. {"info": {"type": "name"}}. not safe for
+// concurrent use, metrics are meant to be registered in init
+func RegisterMetric(name string, metric Metric) {
+	metrics[strings.ToLower(name)] = metric
+}
+
+// returns the metric of a problem type, problems without a type are euclidean
+func lookupMetric(problemType string) (Metric, error) {
+	if problemType == "" {
+		return metrics[Euclidean], nil
+	}
+	metric, ok := metrics[strings.ToLower(problemType)]
+	if !ok {
+		names := make([]string, 0, len(metrics))
+		for name := range metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return Metric{}, fmt.Errorf("unknown problem type %s, expected one of %s", problemType, strings.Join(names, ", "))
+	}
+	return metric, nil
+}
+
+func manhattan(p1, p2 Point) float64 {
+	return math.Abs(p1.X-p2.X) + math.Abs(p1.Y-p2.Y)
+}
+
+func chebyshev(p1, p2 Point) float64 {
+	return math.Max(math.Abs(p1.X-p2.X), math.Abs(p1.Y-p2.Y))
+}
+
+func roundedEuclidean(p1, p2 Point) float64 {
+	return math.Floor(euclidean(p1, p2) + 0.5)
+}
+
+func ceilEuclidean(p1, p2 Point) float64 {
+	return math.Ceil(euclidean(p1, p2))
+}
+
+// the euclidean distance scaled down by the square root of ten and rounded up to an integer
+func att(p1, p2 Point) float64 {
+	r := math.Sqrt((math.Pow(p1.X-p2.X, 2) + math.Pow(p1.Y-p2.Y, 2)) / 10)
+	t := math.Floor(r + 0.5)
+	if t < r {
+		return t + 1
+	}
+	return t
+}
+
+// the radius of the earth and the value of pi that tsplib uses for GEO
+const (
+	tsplibEarthRadius = 6378.388
+	tsplibPi          = 3.141592
+)
+
+// the great-circle distance as defined by tsplib, x is the longitude and y the latitude
+func tsplibGeo(p1, p2 Point) float64 {
+	// degrees and minutes to radians, the degrees are truncated as in the reference implementation
+	radians := func(x float64) float64 {
+		degrees := math.Trunc(x)
+		minutes := x - degrees
+		return tsplibPi * (degrees + 5*minutes/3) / 180
+	}
+
+	lat1, lon1 := radians(p1.Y), radians(p1.X)
+	lat2, lon2 := radians(p2.Y), radians(p2.X)
+	q1 := math.Cos(lon1 - lon2)
+	q2 := math.Cos(lat1 - lat2)
+	q3 := math.Cos(lat1 + lat2)
+	return math.Trunc(tsplibEarthRadius*math.Acos(0.5*((1+q1)*q2-(1-q1)*q3)) + 1)
+}
+
+// semi-major axis in kilometers and flattening of the wgs-84 ellipsoid
+const (
+	wgs84A = 6378.137
+	wgs84F = 1 / 298.257223563
+)
+
+// the distance on the wgs-84 ellipsoid using the inverse formula of vincenty, x is the longitude and
+// y the latitude. falls back to the haversine formula for nearly antipodal points, where the
+// iteration does not converge
+func vincenty(p1, p2 Point) float64 {
+	deg2rad := func(deg float64) float64 { return (math.Pi * deg) / 180 }
+	b := (1 - wgs84F) * wgs84A
+
+	l := deg2rad(p2.X - p1.X)
+	u1 := math.Atan((1 - wgs84F) * math.Tan(deg2rad(p1.Y)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(deg2rad(p2.Y)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	for i := 0; i < 200; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Sqrt(math.Pow(cosU2*sinLambda, 2) + math.Pow(cosU1*sinU2-sinU1*cosU2*cosLambda, 2))
+		if sinSigma == 0 {
+			return 0
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+
+		// both points on the equator
+		cos2SigmaM := 0.0
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+
+		c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) > 1e-12 {
+			continue
+		}
+
+		uSquared := cos2Alpha * (wgs84A*wgs84A - b*b) / (b * b)
+		bigA := 1 + uSquared/16384*(4096+uSquared*(-768+uSquared*(320-175*uSquared)))
+		bigB := uSquared / 1024 * (256 + uSquared*(-128+uSquared*(74-47*uSquared)))
+		deltaSigma := bigB * sinSigma * (cos2SigmaM + bigB/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			bigB/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return b * bigA * (sigma - deltaSigma)
+	}
+
+	return haversine(p1, p2)
+}
@@ -0,0 +1,105 @@
+package problem
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	p1, p2 := Point{X: 0, Y: 0}, Point{X: 3, Y: 4.2}
+	distances := map[string]float64{
+		Euclidean:        math.Sqrt(9 + 4.2*4.2),
+		Manhattan:        7.2,
+		Chebyshev:        4.2,
+		RoundedEuclidean: 5,
+		CeilEuclidean:    6,
+		"EUC_2D":         5,
+		"man_2d":         7.2,
+	}
+	for name, expected := range distances {
+		metric, err := lookupMetric(name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if actual := metric.Distance(p1, p2); math.Abs(actual-expected) > 1e-9 {
+			t.Fatalf("%s: distance is %f, expected %f", name, actual, expected)
+		}
+	}
+
+	// sqrt(100 / 10) rounds to 3, which is rounded up to 4
+	if d := att(Point{X: 0, Y: 0}, Point{X: 10, Y: 0}); d != 4 {
+		t.Fatalf("att distance is %f, expected 4", d)
+	}
+}
+
+func TestMetricTSPLIBGeo(t *testing.T) {
+	// ulysses16 of tsplib as latitude and longitude in degrees and minutes
+	coordinates := [][2]float64{
+		{38.24, 20.42}, {39.57, 26.15}, {40.56, 25.32}, {36.26, 23.12}, {33.48, 10.54}, {37.56, 12.19},
+		{38.42, 13.11}, {37.52, 20.44}, {41.23, 9.10}, {41.17, 13.05}, {36.08, -5.21}, {38.47, 15.13},
+		{38.15, 15.35}, {37.51, 15.17}, {35.49, 14.32}, {39.36, 19.56},
+	}
+	tour := []int{1, 14, 13, 12, 7, 6, 15, 5, 11, 9, 10, 16, 3, 2, 4, 8}
+
+	length := 0.0
+	for i := range tour {
+		from, to := coordinates[tour[i]-1], coordinates[tour[(i+1)%len(tour)]-1]
+		length += tsplibGeo(Point{X: from[1], Y: from[0]}, Point{X: to[1], Y: to[0]})
+	}
+	if length != 6859 {
+		t.Fatalf("optimal tour of ulysses16 has length %f, expected 6859", length)
+	}
+}
+
+func TestMetricVincenty(t *testing.T) {
+	// flinders peak to buninyong, the example of vincenty's inverse formula
+	flinders := Point{X: 144 + 25./60 + 29.52440/3600, Y: -(37 + 57./60 + 3.72030/3600)}
+	buninyong := Point{X: 143 + 55./60 + 35.38390/3600, Y: -(37 + 39./60 + 10.15610/3600)}
+	if d := vincenty(flinders, buninyong); math.Abs(d-54.972271) > 1e-6 {
+		t.Fatalf("distance from flinders peak to buninyong is %f, expected 54.972271", d)
+	}
+
+	if d := vincenty(flinders, flinders); d != 0 {
+		t.Fatalf("distance of a point to itself is %f", d)
+	}
+
+	// nearly antipodal points do not converge and fall back to haversine
+	p1, p2 := Point{X: 0, Y: 0}, Point{X: 179.7, Y: 0.5}
+	if d := vincenty(p1, p2); math.IsNaN(d) || d < 19900 || d > 20100 {
+		t.Fatalf("distance between nearly antipodal points is %f", d)
+	}
+}
+
+func TestMetricProblemType(t *testing.T) {
+	RegisterMetric("Constant", Metric{Distance: func(p1, p2 Point) float64 { return 1 }})
+	defer delete(metrics, "constant")
+
+	problems := map[string]float64{
+		"manhattan.json": 7,
+		"constant.json":  1,
+	}
+	content := map[string]string{
+		"manhattan.json": `{"info": {"type": "manhattan"}, "points": [{"x": 0, "y": 0}, {"x": 3, "y": 4}]}`,
+		"constant.json":  `{"info": {"type": "constant"}, "points": [{"x": 0, "y": 0}, {"x": 3, "y": 4}]}`,
+	}
+	for name, expected := range problems {
+		path := writeFile(t, name, content[name])
+		defer os.RemoveAll(filepath.Dir(path))
+
+		problem, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if d := problem.Adjacency[0][1]; d != expected {
+			t.Fatalf("%s: distance is %f, expected %f", name, d, expected)
+		}
+	}
+
+	path := writeFile(t, "unknown.json", `{"info": {"type": "euclidian"}, "points": [{"x": 0, "y": 0}, {"x": 3, "y": 4}]}`)
+	defer os.RemoveAll(filepath.Dir(path))
+	if _, err := FromFile(path); err == nil {
+		t.Fatalf("expected error for unknown problem type")
+	}
+}
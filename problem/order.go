@@ -0,0 +1,74 @@
+package problem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// returns the position of point i in the problem file
+func (p *Problem) fileIndex(i int) int {
+	if len(p.order) != len(p.Points) {
+		return i
+	}
+	return p.order[i]
+}
+
+// returns the position of every point in the problem file, in the current order of the points
+func (p *Problem) Order() []int {
+	order := make([]int, len(p.Points))
+	for i := range order {
+		order[i] = p.fileIndex(i)
+	}
+	return order
+}
+
+// arranges the points in the given order of positions in the problem file, e.g. the order of a
+// previous run, and recalculates the adjacency. cycles of the previous run are valid afterwards
+func (p *Problem) SetOrder(order []int) error {
+	n := len(p.Points)
+	if len(order) != n {
+		return fmt.Errorf("order has %d points, the problem has %d", len(order), n)
+	}
+
+	filePoints := make([]Point, n)
+	for i, point := range p.Points {
+		filePoints[p.fileIndex(i)] = point
+	}
+
+	visited := make([]bool, n)
+	points := make([]Point, n)
+	for i, index := range order {
+		if index < 0 || index >= n || visited[index] {
+			return fmt.Errorf("order is not a permutation of the points")
+		}
+		visited[index] = true
+		points[i] = filePoints[index]
+	}
+
+	p.Points = points
+	p.order = append([]int(nil), order...)
+	p.calculateAdjacency()
+	return nil
+}
+
+// returns a hash of the type, the points and the given distances of the problem in the order of the
+// problem file, which identifies the problem independently of the order of the points in memory
+func (p *Problem) Hash() string {
+	n := len(p.Points)
+	filePoints := make([]Point, n)
+	for i, point := range p.Points {
+		filePoints[p.fileIndex(i)] = point
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n", strings.ToLower(p.Info.Type))
+	for _, point := range filePoints {
+		fmt.Fprintf(hash, "%v %v %q\n", point.X, point.Y, point.Name)
+	}
+	for _, row := range p.weights {
+		fmt.Fprintf(hash, "%v\n", row)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package problem
+
+import (
+	"testing"
+)
+
+func TestProblemSetOrder(t *testing.T) {
+	first, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+	second, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+
+	// the points of the second problem are arranged like the points of the first one
+	if err := second.SetOrder(first.Order()); err != nil {
+		t.Fatal(err)
+	}
+	for i := range first.Points {
+		if first.Points[i] != second.Points[i] {
+			t.Fatalf("point %d differs: %v, %v", i, first.Points[i], second.Points[i])
+		}
+		for j := range first.Points {
+			if first.Adjacency[i][j] != second.Adjacency[i][j] {
+				t.Fatalf("distance between %d and %d differs", i, j)
+			}
+		}
+	}
+
+	for _, order := range [][]int{{0, 1, 2}, {0, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}} {
+		if err := second.SetOrder(order); err == nil {
+			t.Fatalf("expected error for order %v", order)
+		}
+	}
+}
+
+func TestProblemHash(t *testing.T) {
+	germany, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+	workpiece, err := FromFile(TestProblemFileWorkpiece)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileWorkpiece, err)
+	}
+
+	// the hash does not depend on the order of the points in memory
+	hash := germany.Hash()
+	order := germany.Order()
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	if err := germany.SetOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if germany.Hash() != hash {
+		t.Fatalf("hash changed with the order of the points")
+	}
+
+	if workpiece.Hash() == hash {
+		t.Fatalf("different problems have the same hash")
+	}
+	germany.Points[0].X += 1
+	if germany.Hash() == hash {
+		t.Fatalf("hash did not change with the coordinates")
+	}
+}
@@ -1,14 +1,16 @@
 package problem
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 )
 
 const (
@@ -19,6 +21,31 @@ const (
 // contains the distances between each point on a route
 type Adjacency [][]float64
 
+// calculates the length of a cycle, including the way back from the last to the first point
+func (a Adjacency) Distance(cycle Cycle) float64 {
+	var distance float64
+	for i := range cycle {
+		if i == len(cycle)-1 {
+			distance += a[cycle[i]][cycle[0]]
+		} else {
+			distance += a[cycle[i]][cycle[i+1]]
+		}
+	}
+	return distance
+}
+
+// tells if the distance from every point to another one is the same as the distance back
+func (a Adjacency) Symmetric() bool {
+	for i := range a {
+		for j := i + 1; j < len(a); j++ {
+			if a[i][j] != a[j][i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // a cycle is a set of integers that are to be mapped to points
 type Cycle []int
 
@@ -53,8 +80,16 @@ type Problem struct {
 
 	ShortestDistance float64 `json:"shortestDistance"`
 
-	// adjacency matrix, e.g. distances between the points
+	// adjacency matrix, e.g. distances between the points. problem files may give the distances as
+	// a full or triangular matrix, as "adjacency" or "weights", instead of calculating them from
+	// the coordinates of the points
 	Adjacency Adjacency `json:"adjacency"`
+
+	// position of every point in the problem file, points are shuffled when the problem is loaded
+	order []int
+
+	// distances given by the problem file, in the order of the file
+	weights Adjacency
 }
 
 // contains information about a problem
@@ -62,9 +97,16 @@ type Info struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 
-	// either 'geographic' or 'euclidean'
+	// e.g. 'geographic' or 'euclidean', see RegisterMetric for the other types. defaults to 'euclidean'
 	// determines how distance between two points is calculated
 	Type string `json:"type"`
+
+	// axis convention of geographic points given as x and y, either 'lonlat' (default) or 'latlon'
+	Axes string `json:"axes,omitempty"`
+
+	// the distances given by the problem file depend on the direction, e.g. because of one-way streets.
+	// only algorithms that implement algorithm.Asymmetric solve such problems
+	Asymmetric bool `json:"asymmetric,omitempty"`
 }
 
 type Image struct {
@@ -77,11 +119,15 @@ type Image struct {
 	Height int     `json:"height"`
 }
 
-// a point in two-dimensional space
+// a point in two-dimensional space. points of geographic problems have the longitude as x and the
+// latitude as y, in files they can also be given as lat and lon
 type Point struct {
 	X    float64 `json:"x"`
 	Y    float64 `json:"y"`
 	Name string  `json:"name"`
+
+	// the coordinates were given as lat and lon, they do not depend on the declared axes
+	latLon bool
 }
 
 type Status struct {
@@ -91,12 +137,27 @@ type Status struct {
 	Elapsed     string  `json:"elapsed"`
 	Shortest    float64 `json:"shortest"`
 	Running     bool    `json:"running"`
+
+	// lower bound for the length of the shortest cycle and by how many percent shortest exceeds it
+	LowerBound float64 `json:"lowerBound"`
+	Gap        float64 `json:"gap"`
+
+	// progress reported by the algorithm, statistics depend on the algorithm
+	Iteration int                `json:"iteration"`
+	Phase     string             `json:"phase"`
+	Stats     map[string]float64 `json:"stats"`
 }
 
 func NewProblem(points []Point) *Problem {
+	return NewProblemWithRandom(points, newRandom())
+}
+
+// creates a problem from points, which are shuffled using the given source of randomness
+func NewProblemWithRandom(points []Point, random *rand.Rand) *Problem {
 	p := Problem{
 		Points: points,
 	}
+	p.shuffle(random)
 	p.calculateAdjacency()
 	return &p
 }
@@ -132,9 +193,9 @@ func FromDir(dir string) ([]Problem, error) {
 			continue
 		}
 
-		// skip files that don't end with "json"
+		// skip files that are neither "json" nor tsplib problems
 		absFilePath := filepath.Join(dir, file.Name())
-		if filepath.Ext(absFilePath) != ".json" {
+		if filepath.Ext(absFilePath) != ".json" && !isTSPLIBProblem(absFilePath) {
 			continue
 		}
 
@@ -149,8 +210,14 @@ func FromDir(dir string) ([]Problem, error) {
 	return problems, nil
 }
 
-// loads a problem from a file
+// loads a problem from a file, files with the extension ".tsp" or ".atsp" are read as tsplib problems
 func FromFile(file string) (Problem, error) {
+	return FromFileWithRandom(file, newRandom())
+}
+
+// loads a problem from a file, the points are shuffled using the given source of randomness so
+// that runs with the same seed see the points in the same order
+func FromFileWithRandom(file string, random *rand.Rand) (Problem, error) {
 	// stat file to test if it's accessible
 	if file, err := os.Stat(file); err != nil {
 		return Problem{}, err
@@ -159,60 +226,95 @@ func FromFile(file string) (Problem, error) {
 	}
 
 	// read file
-	bytes, err := ioutil.ReadFile(file)
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
 		return Problem{}, err
 	}
 
-	// parse json to problem
+	// parse tsplib or json to problem
 	var problem = Problem{}
-	err = json.Unmarshal(bytes, &problem)
-	if err != nil {
-		return Problem{}, err
+	if isTSPLIBProblem(file) {
+		problem, err = parseTSPLIB(bytes.NewReader(data))
+		if err != nil {
+			return Problem{}, fmt.Errorf("invalid problem %s: %v", file, err)
+		}
+	} else {
+		err = json.Unmarshal(data, &problem)
+		if err != nil {
+			return Problem{}, err
+		}
+		if err := problem.readWeights(data); err != nil {
+			return Problem{}, fmt.Errorf("invalid problem %s: %v", file, err)
+		}
+	}
+	if _, err := lookupMetric(problem.Info.Type); err != nil {
+		return Problem{}, fmt.Errorf("invalid problem %s: %v", file, err)
+	}
+	if err := problem.normalizeCoordinates(); err != nil {
+		return Problem{}, fmt.Errorf("invalid problem %s: %v", file, err)
 	}
 
-	// calculate adjacency and return
+	// shuffle, calculate adjacency and return
+	problem.shuffle(random)
 	problem.calculateAdjacency()
 	return problem, nil
 }
 
 func (p *Problem) UpdateRoute(cycle Cycle) {
+	p.SetRoute(cycle, p.Adjacency.Distance(cycle))
+}
+
+// sets the shortest route to the given cycle, whose distance is already known
+func (p *Problem) SetRoute(cycle Cycle, distance float64) {
 	// set new route
 	route := make(Route, len(cycle))
 	for i, j := range cycle {
 		route[i] = p.Points[j]
 	}
 	p.ShortestRoute = route
+	p.ShortestDistance = distance
+}
 
-	// calculate new distance
-	var distance float64
-	for i := range cycle {
-		if i == len(cycle)-1 {
-			distance += p.Adjacency[cycle[i]][cycle[0]]
-		} else {
-			distance += p.Adjacency[cycle[i]][cycle[i+1]]
-		}
+// returns a source of randomness seeded with the current time, for callers that do not need
+// reproducible runs
+func newRandom() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// shuffles the points before calculating adjacency, keeps track of the original positions
+func (p *Problem) shuffle(random *rand.Rand) {
+	p.order = make([]int, len(p.Points))
+	for i := range p.order {
+		p.order[i] = i
 	}
-	p.ShortestDistance = distance
+	random.Shuffle(len(p.Points), func(i, j int) {
+		p.Points[i], p.Points[j] = p.Points[j], p.Points[i]
+		p.order[i], p.order[j] = p.order[j], p.order[i]
+	})
 }
 
 // calculates the adjacency matrix of the problem with given points
-// 		- uses the haversine-formula to calculate distances for "geographic" problems
-// 		- uses euclidean distance for "euclidean" problems
+// 		- uses the metric that is selected by the type of the problem, see RegisterMetric
+// 		- e.g. the haversine-formula for "geographic" and euclidean distance for "euclidean" problems
+// 		- the type of problems loaded from files is validated, others of an unknown type are euclidean
+// 		- distances given by the problem file are arranged in the order of the points instead
 func (p *Problem) calculateAdjacency() {
-	var calcDistance func(p1, p2 Point) float64
-
-	switch pType := strings.ToLower(p.Info.Type); pType {
-	case Geographic:
-		calcDistance = haversine
-	case Euclidean:
-		calcDistance = euclidean
-	default:
-		calcDistance = euclidean
+	if p.Explicit() {
+		p.Adjacency = make(Adjacency, len(p.Points))
+		for i := range p.Points {
+			p.Adjacency[i] = make([]float64, len(p.Points))
+			for j := range p.Points {
+				p.Adjacency[i][j] = p.weights[p.fileIndex(i)][p.fileIndex(j)]
+			}
+		}
+		return
 	}
 
-	// shuffle before calculating adjacency
-	rand.Shuffle(len(p.Points), func(i, j int) { p.Points[i], p.Points[j] = p.Points[j], p.Points[i] })
+	metric, err := lookupMetric(p.Info.Type)
+	if err != nil {
+		metric = metrics[Euclidean]
+	}
+	calcDistance := metric.Distance
 
 	// allocate adjacency and calculate distances
 	p.Adjacency = make(Adjacency, len(p.Points))
@@ -230,14 +332,15 @@ func (p *Problem) calculateAdjacency() {
 // the earths radius in kilometer, used to calculate distances on spheres using the haversine formula
 const EarthRadius = 6371
 
-// calculates the shortest point between two points located on a sphere (the earth)
+// calculates the shortest distance between two points located on a sphere (the earth), x is the
+// longitude and y the latitude
 func haversine(p1, p2 Point) float64 {
 	deg2rad := func(deg float64) float64 { return (math.Pi * deg) / 180 }
 
-	lat1 := deg2rad(p1.X)
-	lat2 := deg2rad(p2.X)
-	long1 := deg2rad(p1.Y)
-	long2 := deg2rad(p2.Y)
+	lat1 := deg2rad(p1.Y)
+	lat2 := deg2rad(p2.Y)
+	long1 := deg2rad(p1.X)
+	long2 := deg2rad(p2.X)
 
 	deltaLong := long1 - long2
 	deltaLat := lat1 - lat2
@@ -256,23 +359,32 @@ func euclidean(p1, p2 Point) float64 {
 }
 
 func (p *Problem) MapRouteToImageCoordinates() []int {
-	coordinates := make([]int, 2*len(p.ShortestRoute))
+	return p.mapToImageCoordinates(p.ShortestRoute)
+}
+
+// maps all points of the problem, in the order of p.Points, to coordinates on the image
+func (p *Problem) MapPointsToImageCoordinates() []int {
+	return p.mapToImageCoordinates(p.Points)
+}
+
+func (p *Problem) mapToImageCoordinates(points []Point) []int {
+	coordinates := make([]int, 2*len(points))
 
-	if p.Info.Type == Geographic {
+	if p.geographic() {
 		xDiff := math.Abs(p.Image.X1 - p.Image.X2)
 		yDiff := math.Abs(p.Image.Y1 - p.Image.Y2)
 
 		xPixel := float64(p.Image.Width) / xDiff
 		yPixel := float64(p.Image.Height) / yDiff
 
-		for i, point := range p.ShortestRoute {
+		for i, point := range points {
 			x := (point.X - p.Image.X1) * xPixel
 			y := (p.Image.Y1 - point.Y) * yPixel
 			coordinates[i*2] = int(x)
 			coordinates[i*2+1] = int(y)
 		}
 	} else {
-		for i, point := range p.ShortestRoute {
+		for i, point := range points {
 			coordinates[i*2] = int(point.X)
 			coordinates[i*2+1] = int(point.Y)
 		}
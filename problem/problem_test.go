@@ -1,7 +1,14 @@
 package problem
 
 import (
+	"bytes"
+	"log"
 	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -158,10 +165,10 @@ func TestProblemFromPointsEuclidean(t *testing.T) {
 	cartesianProblem.calculateAdjacency()
 
 	expectedAdj := [][]float64{
-		{0, 3.387226003679116, 6.886080162182256, 3.9865022262630228},
-		{3.387226003679116, 0, 4.408832044884447, 4.7240342928475885},
-		{6.886080162182256, 4.408832044884447, 0, 5.0843386983953005},
-		{3.9865022262630228, 4.7240342928475885, 5.0843386983953005, 0},
+		{0, 3.387226003679116, 3.9865022262630228, 6.886080162182256},
+		{3.387226003679116, 0, 4.7240342928475885, 4.408832044884447},
+		{3.9865022262630228, 4.7240342928475885, 0, 5.0843386983953005},
+		{6.886080162182256, 4.408832044884447, 5.0843386983953005, 0},
 	}
 
 	for i, row := range expectedAdj {
@@ -189,10 +196,10 @@ func TestProblemFromPointsGeographic(t *testing.T) {
 	geographicProblem.calculateAdjacency()
 
 	expectedAdj := [][]float64{
-		{0, 375.94456892271836, 764.9363495938279, 435.41080570770396},
-		{375.94456892271836, 0, 488.19591094433196, 516.9268227447614},
-		{764.9363495938279, 488.19591094433196, 0, 564.5107479834015},
-		{435.41080570770396, 516.9268227447614, 564.5107479834015, 0},
+		{0, 244.8629947933397, 412.46366944762326, 500.75869037798685},
+		{244.8629947933397, 0, 512.3173613114652, 387.3871635371315},
+		{412.46366944762326, 512.3173613114652, 0, 394.9650760296452},
+		{500.75869037798685, 387.3871635371315, 394.9650760296452, 0},
 	}
 
 	for i, row := range expectedAdj {
@@ -203,3 +210,118 @@ func TestProblemFromPointsGeographic(t *testing.T) {
 		}
 	}
 }
+
+func TestProblemFromFileWithRandom(t *testing.T) {
+	load := func(seed int64) Problem {
+		problem, err := FromFileWithRandom(TestProblemFileGermany, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+		}
+		return problem
+	}
+
+	// the same seed results in the same order of the points
+	first, second, other := load(42), load(42), load(43)
+	if !reflect.DeepEqual(first.Points, second.Points) || !reflect.DeepEqual(first.Adjacency, second.Adjacency) {
+		t.Fatalf("problems loaded with the same seed differ")
+	}
+	if reflect.DeepEqual(first.Order(), other.Order()) {
+		t.Fatalf("problems loaded with different seeds have the same order")
+	}
+}
+
+func TestProblemGermanyGreatCircle(t *testing.T) {
+	problem, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+
+	positions := make(map[string]int)
+	for i, point := range problem.Points {
+		positions[point.Name] = i
+	}
+
+	// great-circle distances between the city centres in kilometers
+	distances := []struct {
+		from, to string
+		distance float64
+	}{
+		{"Berlin", "München", 504},
+		{"Berlin", "Hamburg", 255},
+		{"Köln", "Frankfurt", 152},
+		{"Hamburg", "München", 612},
+	}
+	for _, d := range distances {
+		if actual := problem.Adjacency[positions[d.from]][positions[d.to]]; math.Abs(actual-d.distance) > 2 {
+			t.Fatalf("distance from %s to %s is %f, expected about %f", d.from, d.to, actual, d.distance)
+		}
+	}
+}
+
+func TestProblemCoordinates(t *testing.T) {
+	problems := map[string]string{
+		"latlon.json": `{"info": {"type": "geographic"}, "points": [{"lat": 52.5246, "lon": 13.40514}, {"lat": 48.1374, "lon": 11.5755}]}`,
+		"xy.json":     `{"info": {"type": "geographic"}, "points": [{"x": 13.40514, "y": 52.5246}, {"x": 11.5755, "y": 48.1374}]}`,
+		"axes.json":   `{"info": {"type": "geographic", "axes": "latlon"}, "points": [{"x": 52.5246, "y": 13.40514}, {"x": 48.1374, "y": 11.5755}]}`,
+	}
+
+	// all conventions result in the distance from berlin to munich
+	for name, content := range problems {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		problem, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if d := problem.Adjacency[0][1]; math.Abs(d-504.78) > 0.01 {
+			t.Fatalf("%s: wrong distance %f", name, d)
+		}
+	}
+
+	invalid := map[string]string{
+		"range.json":   `{"info": {"type": "geographic"}, "points": [{"lat": 91, "lon": 0}, {"lat": 0, "lon": 0}]}`,
+		"swapped.json": `{"info": {"type": "geographic"}, "points": [{"x": 40.7, "y": -74.0}, {"x": 34.1, "y": -118.2}]}`,
+		"both.json":    `{"info": {"type": "geographic"}, "points": [{"x": 1, "y": 2, "lat": 1, "lon": 2}]}`,
+		"half.json":    `{"info": {"type": "geographic"}, "points": [{"lat": 1}]}`,
+		"axes.json":    `{"info": {"type": "geographic", "axes": "xy"}, "points": [{"x": 1, "y": 2}]}`,
+	}
+	for name, content := range invalid {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if _, err := FromFile(path); err == nil {
+			t.Fatalf("%s: expected error for invalid coordinates", name)
+		}
+	}
+}
+
+func TestProblemCoordinatesSwappedOnImage(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	// berlin and munich with swapped axes are within [-90, 90] either way, only the image of germany
+	// shows that they are swapped
+	image := `"image": {"x1": 5.5, "y1": 55.1, "x2": 15.5, "y2": 47.2, "width": 1000, "height": 1186}`
+	problems := map[string]bool{
+		`{"info": {"type": "geographic"}, ` + image + `, "points": [{"x": 52.5, "y": 13.4}, {"x": 48.1, "y": 11.6}]}`:                   true,
+		`{"info": {"type": "geographic", "axes": "lonlat"}, ` + image + `, "points": [{"x": 52.5, "y": 13.4}, {"x": 48.1, "y": 11.6}]}`: true,
+		`{"info": {"type": "geographic", "axes": "latlon"}, ` + image + `, "points": [{"x": 52.5, "y": 13.4}, {"x": 48.1, "y": 11.6}]}`: false,
+		`{"info": {"type": "geographic", "axes": "lonlat"}, ` + image + `, "points": [{"x": 13.4, "y": 52.5}, {"x": 48.1, "y": 11.6}]}`: false,
+		`{"info": {"type": "geographic", "axes": "lonlat"}, "points": [{"x": 52.5, "y": 13.4}, {"x": 48.1, "y": 11.6}]}`:                false,
+		`{"info": {"type": "geographic"}, ` + image + `, "points": [{"lat": 13.4, "lon": 52.5}, {"lat": 11.6, "lon": 48.1}]}`:           false,
+	}
+	for content, swapped := range problems {
+		path := writeFile(t, "swapped.json", content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		logged.Reset()
+		if _, err := FromFile(path); err != nil {
+			t.Fatalf("%s: %s", content, err)
+		}
+		if warned := strings.Contains(logged.String(), "axes look swapped"); warned != swapped {
+			t.Fatalf("%s: warned about swapped axes: %t, expected %t", content, warned, swapped)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package problem
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// reads a tour from a file and converts it to a cycle of the problem. files with the extension
+// ".tour" are read as tsplib tours, whose nodes are numbered from 1 in the order of the problem
+// file. other files contain a json list of either point names or indices into the points of the
+// problem file, e.g. ["Berlin", "Hamburg", "München"] or [0, 2, 1]
+func (p *Problem) LoadTour(path string) (Cycle, error) {
+	cycle, err := p.loadTour(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tour %s: %v", path, err)
+	}
+	return cycle, nil
+}
+
+func (p *Problem) loadTour(path string) (Cycle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".tour" {
+		indices, err := parseTSPLIBTour(file)
+		if err != nil {
+			return nil, err
+		}
+		return p.cycleFromIndices(indices)
+	}
+
+	bytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []int
+	if err := json.Unmarshal(bytes, &indices); err == nil {
+		return p.cycleFromIndices(indices)
+	}
+	var names []string
+	if err := json.Unmarshal(bytes, &names); err != nil {
+		return nil, errors.New("expected a list of point names or indices")
+	}
+	return p.cycleFromNames(names)
+}
+
+// converts indices into the points of the problem file to a cycle, fails if the indices do not
+// visit every point exactly once
+func (p *Problem) cycleFromIndices(indices []int) (Cycle, error) {
+	n := len(p.Points)
+	if len(indices) != n {
+		return nil, fmt.Errorf("tour visits %d points, the problem has %d", len(indices), n)
+	}
+
+	// points are shuffled, find the current position of every point of the file
+	positions := make([]int, n)
+	for i := range positions {
+		positions[p.fileIndex(i)] = i
+	}
+
+	cycle := make(Cycle, n)
+	visited := make([]bool, n)
+	for i, index := range indices {
+		if index < 0 || index >= n {
+			return nil, fmt.Errorf("point %d does not exist", index)
+		}
+		if visited[index] {
+			return nil, fmt.Errorf("point %d is visited twice", index)
+		}
+		visited[index] = true
+		cycle[i] = positions[index]
+	}
+
+	return cycle, nil
+}
+
+// converts point names to a cycle, the names of the points must be unique
+func (p *Problem) cycleFromNames(names []string) (Cycle, error) {
+	indices := make(map[string]int, len(p.Points))
+	for i, point := range p.Points {
+		if _, ok := indices[point.Name]; ok {
+			return nil, fmt.Errorf("point name \"%s\" is not unique, use indices instead", point.Name)
+		}
+		indices[point.Name] = p.fileIndex(i)
+	}
+
+	tour := make([]int, len(names))
+	for i, name := range names {
+		index, ok := indices[name]
+		if !ok {
+			return nil, fmt.Errorf("point \"%s\" does not exist", name)
+		}
+		tour[i] = index
+	}
+
+	return p.cycleFromIndices(tour)
+}
+
+// reads the nodes of a tsplib tour and converts them to indices starting at 0
+func parseTSPLIBTour(r io.Reader) ([]int, error) {
+	scanner := bufio.NewScanner(r)
+	dimension := -1
+	section := false
+	nodes := make([]int, 0)
+
+Lines:
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// specification part, keywords of the form "KEY : value"
+		if !section {
+			key, value := splitTSPLIBKeyword(line)
+			switch key {
+			case "TYPE":
+				if !strings.EqualFold(value, "TOUR") {
+					return nil, fmt.Errorf("expected type TOUR, got %s", value)
+				}
+			case "DIMENSION":
+				d, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid dimension: %s", value)
+				}
+				dimension = d
+			case "TOUR_SECTION":
+				section = true
+			case "EOF":
+				break Lines
+			}
+			continue
+		}
+
+		// tour section, nodes are numbered from 1 and the tour is terminated by -1
+		for _, field := range strings.Fields(line) {
+			node, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid node: %s", field)
+			}
+			if node == -1 {
+				break Lines
+			}
+			nodes = append(nodes, node-1)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !section {
+		return nil, errors.New("missing TOUR_SECTION")
+	}
+	if dimension >= 0 && len(nodes) != dimension {
+		return nil, fmt.Errorf("tour has %d nodes, expected dimension %d", len(nodes), dimension)
+	}
+	return nodes, nil
+}
+
+// splits a line of the specification part of a tsplib file into its upper-case keyword and value
+func splitTSPLIBKeyword(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+	return strings.ToUpper(strings.TrimSpace(line[:colon])), strings.TrimSpace(line[colon+1:])
+}
@@ -0,0 +1,82 @@
+package problem
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// a route through the thirteen cities of the germany problem
+var germanyTour = []string{"Berlin", "Leipzig", "Hannover", "Hamburg", "Bremen", "Dortmund", "Essen",
+	"Düsseldorf", "Köln", "Frankfurt", "Stuttgart", "München", "Dresden"}
+
+// writes content to a temporary file with the given name and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "tour")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTour(t *testing.T) {
+	problem, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+
+	tours := map[string]string{
+		"names.json":   `["Berlin", "Leipzig", "Hannover", "Hamburg", "Bremen", "Dortmund", "Essen", "Düsseldorf", "Köln", "Frankfurt", "Stuttgart", "München", "Dresden"]`,
+		"indices.json": `[0, 9, 12, 1, 10, 7, 8, 6, 3, 4, 5, 2, 11]`,
+		"germany.tour": "NAME : germany13.tour\nTYPE : TOUR\nDIMENSION : 13\nTOUR_SECTION\n1\n10\n13\n2\n11\n8\n9\n7\n4\n5\n6\n3\n12\n-1\nEOF\n",
+	}
+
+	for name, content := range tours {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		cycle, err := problem.LoadTour(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		// points are shuffled, but the route has to visit the cities in the order of the tour
+		problem.UpdateRoute(cycle)
+		for i, point := range problem.ShortestRoute {
+			if point.Name != germanyTour[i] {
+				t.Fatalf("%s: expected %s at position %d, got %s", name, germanyTour[i], i, point.Name)
+			}
+		}
+	}
+}
+
+func TestLoadTourInvalid(t *testing.T) {
+	problem, err := FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatalf("failed to load problem from file=%s, err=%s", TestProblemFileGermany, err)
+	}
+
+	tours := map[string]string{
+		"short.json":     `[0, 1, 2]`,
+		"twice.json":     `[0, 0, 12, 1, 10, 7, 8, 6, 3, 4, 5, 2, 11]`,
+		"range.json":     `[13, 9, 12, 1, 10, 7, 8, 6, 3, 4, 5, 2, 11]`,
+		"unknown.json":   `["Berlin", "Leipzig", "Hannover", "Hamburg", "Bremen", "Dortmund", "Essen", "Düsseldorf", "Köln", "Frankfurt", "Stuttgart", "München", "Kiel"]`,
+		"object.json":    `{"route": [0, 9, 12]}`,
+		"dimension.tour": "TYPE : TOUR\nDIMENSION : 14\nTOUR_SECTION\n1\n10\n13\n2\n11\n8\n9\n7\n4\n5\n6\n3\n12\n-1\n",
+		"type.tour":      "TYPE : TSP\nTOUR_SECTION\n1\n-1\n",
+	}
+
+	for name, content := range tours {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if _, err := problem.LoadTour(path); err == nil {
+			t.Fatalf("%s: expected error for invalid tour", name)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package problem
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tsplib edge weight types that are calculated from coordinates and the types of problems whose
+// metric calculates them
+var tsplibEdgeWeightTypes = map[string]string{
+	"EUC_2D":  RoundedEuclidean,
+	"CEIL_2D": CeilEuclidean,
+	"GEO":     TSPLIBGeo,
+	"ATT":     ATT,
+	"MAN_2D":  Manhattan,
+	"MAX_2D":  Chebyshev,
+}
+
+// shapes of the tsplib edge weight formats. the column-wise triangles of a symmetric matrix are the
+// row-wise triangles of the other half
+var tsplibEdgeWeightFormats = map[string]int{
+	"FULL_MATRIX":    fullMatrix,
+	"LOWER_DIAG_ROW": lowerDiagonalRows,
+	"LOWER_ROW":      lowerRows,
+	"UPPER_DIAG_ROW": upperDiagonalRows,
+	"UPPER_ROW":      upperRows,
+	"UPPER_DIAG_COL": lowerDiagonalRows,
+	"UPPER_COL":      lowerRows,
+	"LOWER_DIAG_COL": upperDiagonalRows,
+	"LOWER_COL":      upperRows,
+}
+
+// a node of a coordinate section of a tsplib file, numbered from 1
+type tsplibNode struct {
+	node int
+	x, y float64
+}
+
+// tells if a file is a tsplib problem by its extension, e.g. ".tsp" or ".atsp"
+func isTSPLIBProblem(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".tsp" || ext == ".atsp"
+}
+
+// reads a problem in the format of tsplib. supports symmetric and asymmetric problems whose distances
+// are either calculated from the coordinates of the nodes, see tsplibEdgeWeightTypes, or given
+// explicitly in one of tsplibEdgeWeightFormats. display data serves the visualization of the latter.
+// the points are named by the numbers of the nodes
+func parseTSPLIB(r io.Reader) (Problem, error) {
+	var problem Problem
+	var comments []string
+	dimension := -1
+	edgeWeightType, edgeWeightFormat := "", ""
+	section := ""
+	var coordinates, display []tsplibNode
+	var weights []float64
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+Lines:
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// data of the current section, keywords start with a letter
+		if c := line[0]; !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+			fields := strings.Fields(line)
+			switch section {
+			case "NODE_COORD_SECTION", "DISPLAY_DATA_SECTION":
+				node, err := parseTSPLIBNode(fields)
+				if err != nil {
+					return Problem{}, fmt.Errorf("invalid line in %s: %s", section, line)
+				}
+				if section == "NODE_COORD_SECTION" {
+					coordinates = append(coordinates, node)
+				} else {
+					display = append(display, node)
+				}
+			case "EDGE_WEIGHT_SECTION":
+				for _, field := range fields {
+					weight, err := strconv.ParseFloat(field, 64)
+					if err != nil {
+						return Problem{}, fmt.Errorf("invalid edge weight: %s", field)
+					}
+					weights = append(weights, weight)
+				}
+			}
+			continue
+		}
+
+		// specification part, keywords of the form "KEY : value", or the start of a section.
+		// sections that are not needed, e.g. FIXED_EDGES_SECTION, are skipped
+		key, value := splitTSPLIBKeyword(line)
+		section = ""
+		switch key {
+		case "NAME":
+			problem.Info.Name = value
+		case "COMMENT":
+			comments = append(comments, value)
+		case "TYPE":
+			switch strings.ToUpper(value) {
+			case "TSP":
+			case "ATSP":
+				problem.Info.Asymmetric = true
+			default:
+				return Problem{}, fmt.Errorf("unsupported type %s, expected TSP or ATSP", value)
+			}
+		case "DIMENSION":
+			d, err := strconv.Atoi(value)
+			if err != nil || d < 0 {
+				return Problem{}, fmt.Errorf("invalid dimension: %s", value)
+			}
+			dimension = d
+		case "EDGE_WEIGHT_TYPE":
+			edgeWeightType = strings.ToUpper(value)
+		case "EDGE_WEIGHT_FORMAT":
+			edgeWeightFormat = strings.ToUpper(value)
+		case "NODE_COORD_TYPE":
+			if t := strings.ToUpper(value); t != "TWOD_COORDS" && t != "NO_COORDS" {
+				return Problem{}, fmt.Errorf("unsupported node coordinate type %s", value)
+			}
+		case "NODE_COORD_SECTION", "DISPLAY_DATA_SECTION", "EDGE_WEIGHT_SECTION":
+			section = key
+		case "EOF":
+			break Lines
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Problem{}, err
+	}
+
+	if dimension < 0 {
+		return Problem{}, errors.New("missing DIMENSION")
+	}
+	problem.Info.Description = strings.Join(comments, " ")
+	problem.Points = make([]Point, dimension)
+	for i := range problem.Points {
+		problem.Points[i].Name = strconv.Itoa(i + 1)
+	}
+
+	if edgeWeightType == "EXPLICIT" {
+		rows, err := tsplibRows(weights, dimension, edgeWeightFormat)
+		if err != nil {
+			return Problem{}, err
+		}
+		if problem.weights, err = expandMatrix(rows, dimension, problem.Info.Asymmetric); err != nil {
+			return Problem{}, err
+		}
+
+		// coordinates, if any, only serve the visualization
+		if len(display) == 0 {
+			display = coordinates
+		}
+		if len(display) != 0 {
+			if err := problem.placeTSPLIBNodes(display, false); err != nil {
+				return Problem{}, fmt.Errorf("invalid DISPLAY_DATA_SECTION: %v", err)
+			}
+		}
+		return problem, nil
+	}
+
+	problemType, ok := tsplibEdgeWeightTypes[edgeWeightType]
+	if !ok {
+		return Problem{}, fmt.Errorf("unsupported edge weight type %s", edgeWeightType)
+	}
+	if problem.Info.Asymmetric {
+		return Problem{}, errors.New("asymmetric problems need EXPLICIT edge weights")
+	}
+	problem.Info.Type = problemType
+	if err := problem.placeTSPLIBNodes(coordinates, problemType == TSPLIBGeo); err != nil {
+		return Problem{}, fmt.Errorf("invalid NODE_COORD_SECTION: %v", err)
+	}
+	return problem, nil
+}
+
+// reads a line of a coordinate section, the number of the node followed by its coordinates
+func parseTSPLIBNode(fields []string) (tsplibNode, error) {
+	if len(fields) != 3 {
+		return tsplibNode{}, errors.New("expected a node and two coordinates")
+	}
+	node, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return tsplibNode{}, err
+	}
+	x, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return tsplibNode{}, err
+	}
+	y, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return tsplibNode{}, err
+	}
+	return tsplibNode{node: node, x: x, y: y}, nil
+}
+
+// sets the coordinates of every point to the coordinates of its node. the nodes of geographic
+// problems are given as latitude and longitude
+func (p *Problem) placeTSPLIBNodes(nodes []tsplibNode, geographic bool) error {
+	if len(nodes) != len(p.Points) {
+		return fmt.Errorf("%d nodes, expected dimension %d", len(nodes), len(p.Points))
+	}
+
+	placed := make([]bool, len(p.Points))
+	for _, node := range nodes {
+		i := node.node - 1
+		if i < 0 || i >= len(p.Points) {
+			return fmt.Errorf("node %d does not exist", node.node)
+		}
+		if placed[i] {
+			return fmt.Errorf("node %d is given twice", node.node)
+		}
+		placed[i] = true
+
+		if geographic {
+			p.Points[i].X, p.Points[i].Y, p.Points[i].latLon = node.y, node.x, true
+		} else {
+			p.Points[i].X, p.Points[i].Y = node.x, node.y
+		}
+	}
+	return nil
+}
+
+// splits the edge weights of a tsplib file into the rows of the given format. the diagonal of full
+// matrices is set to 0, as atsp files often give a large number instead
+func tsplibRows(weights []float64, n int, format string) (Adjacency, error) {
+	shape, ok := tsplibEdgeWeightFormats[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported edge weight format %s", format)
+	}
+
+	expected := 0
+	for i := 0; i < n; i++ {
+		expected += matrixShapes[shape].length(i, n)
+	}
+	if len(weights) != expected {
+		return nil, fmt.Errorf("%d edge weights, %s of dimension %d has %d", len(weights), format, n, expected)
+	}
+
+	rows := make(Adjacency, n)
+	for i := range rows {
+		length := matrixShapes[shape].length(i, n)
+		rows[i], weights = weights[:length], weights[length:]
+		if shape == fullMatrix {
+			rows[i][i] = 0
+		}
+	}
+	return rows, nil
+}
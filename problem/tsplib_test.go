@@ -0,0 +1,229 @@
+package problem
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const ulysses16 = `NAME: ulysses16.tsp
+TYPE: TSP
+COMMENT: Odyssey of Ulysses (Groetschel/Padberg)
+DIMENSION: 16
+EDGE_WEIGHT_TYPE: GEO
+DISPLAY_DATA_TYPE: COORD_DISPLAY
+NODE_COORD_SECTION
+ 1 38.24 20.42
+ 2 39.57 26.15
+ 3 40.56 25.32
+ 4 36.26 23.12
+ 5 33.48 10.54
+ 6 37.56 12.19
+ 7 38.42 13.11
+ 8 37.52 20.44
+ 9 41.23 9.10
+ 10 41.17 13.05
+ 11 36.08 -5.21
+ 12 38.47 15.13
+ 13 38.15 15.35
+ 14 37.51 15.17
+ 15 35.49 14.32
+ 16 39.36 19.56
+EOF
+`
+
+const ulysses16Tour = `NAME : ulysses16.opt.tour
+TYPE : TOUR
+DIMENSION : 16
+TOUR_SECTION
+1 14 13 12 7 6 15 5 11 9 10 16 3 2 4 8
+-1
+EOF
+`
+
+func TestTSPLIBGeo(t *testing.T) {
+	path := writeFile(t, "ulysses16.tsp", ulysses16)
+	defer os.RemoveAll(filepath.Dir(path))
+	tourPath := filepath.Join(filepath.Dir(path), "ulysses16.opt.tour")
+	if err := ioutil.WriteFile(tourPath, []byte(ulysses16Tour), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	problem, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problem.Info.Name != "ulysses16.tsp" || problem.Info.Type != TSPLIBGeo || len(problem.Points) != 16 {
+		t.Fatalf("wrong problem: %v, %d points", problem.Info, len(problem.Points))
+	}
+
+	// points are named by their nodes, geographic points have the longitude as x
+	for _, point := range problem.Points {
+		if point.Name == "11" && (point.X != -5.21 || point.Y != 36.08) {
+			t.Fatalf("wrong coordinates of node 11: %v", point)
+		}
+	}
+
+	// the optimal tour of tsplib has the known optimal distance
+	cycle, err := problem.LoadTour(tourPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := problem.Adjacency.Distance(cycle); d != 6859 {
+		t.Fatalf("optimal tour has distance %f, expected 6859", d)
+	}
+}
+
+func TestTSPLIBEdgeWeightFormats(t *testing.T) {
+	expected := Adjacency{
+		{0, 4, 7, 3},
+		{4, 0, 2, 5},
+		{7, 2, 0, 6},
+		{3, 5, 6, 0},
+	}
+	formats := map[string]string{
+		"FULL_MATRIX":    "0 4 7 3\n4 0 2 5\n7 2 0 6\n3 5 6 0",
+		"UPPER_ROW":      "4 7 3\n2 5\n6",
+		"LOWER_ROW":      "4\n7 2\n3 5 6",
+		"UPPER_DIAG_ROW": "0 4 7 3 0 2 5 0 6 0",
+		"LOWER_DIAG_ROW": "0 4 0 7 2 0 3 5 6 0",
+		"UPPER_COL":      "4 7 2 3 5 6",
+		"LOWER_COL":      "4 7 3 2 5 6",
+		"UPPER_DIAG_COL": "0 4 0 7 2 0 3 5 6 0",
+		"LOWER_DIAG_COL": "0 4 7 3 0 2 5 0 6 0",
+	}
+
+	for format, weights := range formats {
+		content := "NAME: matrix\nTYPE: TSP\nDIMENSION: 4\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: " + format +
+			"\nDISPLAY_DATA_TYPE: TWOD_DISPLAY\nEDGE_WEIGHT_SECTION\n" + weights +
+			"\nDISPLAY_DATA_SECTION\n1 0 0\n2 10 0\n3 10 10\n4 0 10\nEOF\n"
+		path := writeFile(t, "matrix.tsp", content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		problem, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if !problem.Explicit() {
+			t.Fatalf("%s: distances are not taken from the file", format)
+		}
+		order := problem.Order()
+		for i := range order {
+			for j := range order {
+				if d := problem.Adjacency[i][j]; d != expected[order[i]][order[j]] {
+					t.Fatalf("%s: distance between %d and %d is %f, expected %f", format, order[i], order[j], d,
+						expected[order[i]][order[j]])
+				}
+			}
+			if point := problem.Points[i]; order[i] == 2 && (point.X != 10 || point.Y != 10) {
+				t.Fatalf("%s: wrong display coordinates of node 3: %v", format, point)
+			}
+		}
+	}
+}
+
+func TestTSPLIBAsymmetric(t *testing.T) {
+	content := `NAME: ring
+TYPE: ATSP
+DIMENSION: 3
+EDGE_WEIGHT_TYPE: EXPLICIT
+EDGE_WEIGHT_FORMAT: FULL_MATRIX
+EDGE_WEIGHT_SECTION
+9999 1 9
+9 9999 1
+1 9 9999
+EOF
+`
+	path := writeFile(t, "ring.atsp", content)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	problem, err := FromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !problem.Info.Asymmetric || problem.Adjacency.Symmetric() {
+		t.Fatalf("problem is not asymmetric")
+	}
+
+	// the large numbers on the diagonal are ignored
+	order := problem.Order()
+	for i := range order {
+		if problem.Adjacency[i][i] != 0 {
+			t.Fatalf("distance of node %d to itself is %f", order[i]+1, problem.Adjacency[i][i])
+		}
+		for j := range order {
+			if order[j] == (order[i]+1)%3 && problem.Adjacency[i][j] != 1 {
+				t.Fatalf("distance from node %d to node %d is %f, expected 1", order[i]+1, order[j]+1, problem.Adjacency[i][j])
+			}
+		}
+	}
+}
+
+func TestTSPLIBCoordinates(t *testing.T) {
+	distances := map[string]float64{
+		"EUC_2D":  5,
+		"CEIL_2D": 6,
+		"MAN_2D":  7.4,
+		"MAX_2D":  4.4,
+		"ATT":     2,
+	}
+	for edgeWeightType, expected := range distances {
+		content := "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: " + edgeWeightType + "\nNODE_COORD_SECTION\n2 3 4.4\n1 0 0\nEOF\n"
+		path := writeFile(t, "points.tsp", content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		problem, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("%s: %s", edgeWeightType, err)
+		}
+		if d := problem.Adjacency[0][1]; math.Abs(d-expected) > 1e-9 {
+			t.Fatalf("%s: distance is %f, expected %f", edgeWeightType, d, expected)
+		}
+	}
+
+	invalid := map[string]string{
+		"type.tsp":      "TYPE: CVRP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 3 4\nEOF\n",
+		"weight.tsp":    "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_3D\nNODE_COORD_SECTION\n1 0 0 0\n2 3 4 0\nEOF\n",
+		"dimension.tsp": "TYPE: TSP\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 3 4\nEOF\n",
+		"nodes.tsp":     "TYPE: TSP\nDIMENSION: 3\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n2 3 4\nEOF\n",
+		"twice.tsp":     "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EUC_2D\nNODE_COORD_SECTION\n1 0 0\n1 3 4\nEOF\n",
+		"count.tsp":     "TYPE: TSP\nDIMENSION: 3\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: UPPER_ROW\nEDGE_WEIGHT_SECTION\n1 2\nEOF\n",
+		"format.tsp":    "TYPE: TSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: FUNCTION\nEDGE_WEIGHT_SECTION\n1\nEOF\n",
+		"atsp.atsp":     "TYPE: ATSP\nDIMENSION: 3\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: UPPER_ROW\nEDGE_WEIGHT_SECTION\n1 2 3\nEOF\n",
+	}
+	for name, content := range invalid {
+		path := writeFile(t, name, content)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if _, err := FromFile(path); err == nil {
+			t.Fatalf("%s: expected error for invalid tsplib problem", name)
+		}
+	}
+}
+
+func TestTSPLIBLoadDir(t *testing.T) {
+	path := writeFile(t, "ulysses16.tsp", ulysses16)
+	dir := filepath.Dir(path)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"ulysses16.opt.tour": ulysses16Tour,
+		"ring.atsp":          "TYPE: ATSP\nDIMENSION: 2\nEDGE_WEIGHT_TYPE: EXPLICIT\nEDGE_WEIGHT_FORMAT: FULL_MATRIX\nEDGE_WEIGHT_SECTION\n0 1\n2 0\nEOF\n",
+		"square.json":        `{"points": [{"x": 0, "y": 0}, {"x": 1, "y": 0}, {"x": 1, "y": 1}, {"x": 0, "y": 1}]}`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	problems, err := FromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 3 {
+		t.Fatalf("loaded %d problems, expected 3", len(problems))
+	}
+}
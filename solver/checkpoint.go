@@ -0,0 +1,96 @@
+package solver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+// version of the checkpoint format, checkpoints of other versions are refused
+const checkpointVersion = 1
+
+// where and how often checkpoints of a run are written, and the checkpoint to resume from
+type CheckpointConfig struct {
+	// file the checkpoints are written to, empty to disable checkpoints
+	Path string
+
+	// time between two checkpoints
+	Interval time.Duration
+
+	// checkpoint to resume from, empty to start from scratch
+	Resume string
+}
+
+// the state of a run that is written to disk to resume it later. the state of the search is only
+// present for algorithms that implement algorithm.Checkpointer, other algorithms resume from the
+// shortest cycle
+type Checkpoint struct {
+	Version int `json:"version"`
+
+	// hash of the problem, a checkpoint is only resumed against the same problem
+	ProblemHash string `json:"problemHash"`
+
+	// name of the algorithm as given on the command line
+	Algorithm string `json:"algorithm"`
+
+	// position of every point in the problem file, cycles and states refer to this order
+	Order []int `json:"order"`
+
+	// seconds the run was running
+	Elapsed float64 `json:"elapsed"`
+
+	Cycle     problem.Cycle   `json:"cycle,omitempty"`
+	Distance  float64         `json:"distance"`
+	Iteration int             `json:"iteration"`
+	State     json.RawMessage `json:"state,omitempty"`
+}
+
+// writes a checkpoint to a temporary file that replaces path, so that an interrupted write never
+// leaves a broken checkpoint behind
+func WriteCheckpoint(path string, checkpoint Checkpoint) error {
+	checkpoint.Version = checkpointVersion
+	bytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(bytes); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// reads a checkpoint, refuses checkpoints of other versions and of other problems
+func ReadCheckpoint(path string, p *problem.Problem) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return checkpoint, err
+	}
+	if err := json.Unmarshal(bytes, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+
+	if checkpoint.Version != checkpointVersion {
+		return checkpoint, fmt.Errorf("checkpoint %s has version %d, expected %d", path, checkpoint.Version, checkpointVersion)
+	}
+	if checkpoint.ProblemHash != p.Hash() {
+		return checkpoint, fmt.Errorf("checkpoint %s belongs to a different problem", path)
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+const (
+	TestProblemFileGermany   = "../samples/germany13.json"
+	TestProblemFileWorkpiece = "../samples/workpiece.json"
+)
+
+func TestCheckpoint(t *testing.T) {
+	germany, err := problem.FromFile(TestProblemFileGermany)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workpiece, err := problem.FromFile(TestProblemFileWorkpiece)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "run.json")
+
+	written := Checkpoint{
+		ProblemHash: germany.Hash(),
+		Algorithm:   "bruteforce",
+		Order:       germany.Order(),
+		Elapsed:     12.5,
+		Cycle:       problem.Cycle{0, 1, 2},
+		Distance:    42,
+		Iteration:   7,
+		State:       json.RawMessage(`{"i":3}`),
+	}
+	if err := WriteCheckpoint(path, written); err != nil {
+		t.Fatal(err)
+	}
+
+	// the checkpoint is read back unchanged for the same problem
+	read, err := ReadCheckpoint(path, &germany)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written.Version = checkpointVersion
+	if !reflect.DeepEqual(read, written) {
+		t.Fatalf("checkpoint changed: %+v, %+v", read, written)
+	}
+
+	// and refused for another problem or another version
+	if _, err := ReadCheckpoint(path, &workpiece); err == nil {
+		t.Fatal("expected error for checkpoint of a different problem")
+	}
+	if err := ioutil.WriteFile(path, []byte(`{"version": 0}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCheckpoint(path, &germany); err == nil {
+		t.Fatal("expected error for checkpoint of a different version")
+	}
+
+	// no temporary files are left behind
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("expected only the checkpoint in %s, got %d files", dir, len(files))
+	}
+}
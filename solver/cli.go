@@ -1,11 +1,20 @@
 package solver
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"leistungsnachweis-graphiker/algorithm"
 	"leistungsnachweis-graphiker/problem"
 	"leistungsnachweis-graphiker/web"
 	"log"
 	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,22 +24,118 @@ type CliController struct {
 	problem    problem.Problem
 	startTime  time.Time
 	webHandler *web.Handler
+	pheromones chan [][]float64
+	lowerBound float64
+	progress   algorithm.Progress
+	criteria   StoppingCriteria
+	stopReason string
+
+	// time of the last improvement, for the no-improvement criterion
+	improvedAt time.Time
+
+	// checkpoints of the run, the name of the algorithm is stored along with them
+	algorithmName  string
+	checkpoints    CheckpointConfig
+	lastCheckpoint time.Time
+	cycle          problem.Cycle
+
+	// time the resumed run was running before, it is part of the reported elapsed time but does not
+	// count towards the time limit
+	resumedElapsed time.Duration
+
+	// seed of the source of randomness of the problem and the algorithm
+	seed int64
 }
 
-func NewCli(algorithmName, problemPath, bind string) CliController {
+// edges whose pheromone is below this share of the strongest edge are not sent to the webapp
+const pheromoneThreshold = 0.05
+
+func NewCli(algorithmName, problemPath, initialTourPath, bind string, criteria StoppingCriteria,
+	checkpoints CheckpointConfig, seed int64) CliController {
 	log.Printf("running as cli")
 
-	// try to instantiate algorithm from string
-	alg, err := algorithm.FromString(algorithmName)
+	// the order of the points and every random decision of the algorithm depend on the seed, a run
+	// is reproduced by passing the seed of its final report
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	log.Printf("using seed %d", seed)
+
+	// try to load problem from provided filepath
+	prob, err := problem.FromFileWithRandom(problemPath, random)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// try to load problem from provided filepath
-	prob, err := problem.FromFile(problemPath)
+	// a resumed run continues with the algorithm of the checkpoint, unless another one is given
+	var resumed *Checkpoint
+	if len(checkpoints.Resume) != 0 {
+		if len(initialTourPath) != 0 {
+			log.Fatal("a resumed run cannot start from an initial tour")
+		}
+		checkpoint, err := ReadCheckpoint(checkpoints.Resume, &prob)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := prob.SetOrder(checkpoint.Order); err != nil {
+			log.Fatal(err)
+		}
+		if len(algorithmName) == 0 {
+			algorithmName = checkpoint.Algorithm
+		}
+		if len(checkpoints.Path) == 0 {
+			checkpoints.Path = checkpoints.Resume
+		}
+		resumed = &checkpoint
+		log.Printf("resuming run of %s after %.0fs", checkpoint.Algorithm, checkpoint.Elapsed)
+	}
+
+	// try to instantiate algorithm from string, algorithms that assume symmetric distances are refused
+	// for asymmetric problems
+	alg, err := algorithm.FromStringFor(algorithmName, prob.Adjacency)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if resumed != nil {
+		resume(alg, algorithmName, *resumed)
+	}
+	if randomized, ok := alg.(algorithm.Randomized); ok {
+		randomized.SetRandom(random)
+	}
+
+	// algorithms that are able to continue their search attach their state to the checkpoints
+	if checkpointer, ok := alg.(algorithm.Checkpointer); ok && len(checkpoints.Path) != 0 {
+		checkpointer.SetCheckpointInterval(checkpoints.Interval)
+	}
+
+	// start from an existing tour, e.g. to improve a route after small changes to the problem
+	if len(initialTourPath) != 0 {
+		initialTour, err := prob.LoadTour(initialTourPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("initial tour has distance %f", prob.Adjacency.Distance(initialTour))
+
+		if warmStarter, ok := alg.(algorithm.WarmStarter); ok {
+			warmStarter.SetInitialCycle(initialTour)
+		} else {
+			log.Printf("%s does not start from a given tour, ignoring the initial tour", alg)
+		}
+	}
+
+	// some algorithms work on the coordinates of the points, which do not match given distances
+	if geometric, ok := alg.(algorithm.Geometric); ok && !prob.Explicit() {
+		geometric.SetPoints(prob.Points)
+	}
+
+	// refuse problems that the algorithm is not able to solve
+	if validator, ok := alg.(algorithm.Validator); ok {
+		err = validator.Validate(prob.Adjacency)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// start webhandler?
 	if len(bind) != 0 {
@@ -38,67 +143,195 @@ func NewCli(algorithmName, problemPath, bind string) CliController {
 		if err != nil {
 			log.Fatal(err)
 		}
-		return CliController{algorithm: alg, problem: prob, webHandler: wh}
+		controller := newCliController(alg, prob, criteria, algorithmName, checkpoints, resumed)
+		controller.webHandler = wh
+		controller.seed = seed
+
+		// forward snapshots of the pheromones to render a heat-map
+		if source, ok := alg.(algorithm.PheromoneSource); ok {
+			controller.pheromones = make(chan [][]float64, 1)
+			source.SetPheromones(controller.pheromones)
+		}
+
+		return controller
 	}
 
-	return CliController{algorithm: alg, problem: prob}
+	controller := newCliController(alg, prob, criteria, algorithmName, checkpoints, resumed)
+	controller.seed = seed
+	return controller
+}
+
+func newCliController(alg algorithm.Algorithm, prob problem.Problem, criteria StoppingCriteria, algorithmName string,
+	checkpoints CheckpointConfig, resumed *Checkpoint) CliController {
+	controller := CliController{
+		algorithm:     alg,
+		problem:       prob,
+		criteria:      criteria,
+		algorithmName: algorithmName,
+		checkpoints:   checkpoints,
+	}
+	if resumed != nil {
+		controller.resumedElapsed = time.Duration(resumed.Elapsed * float64(time.Second))
+	}
+	return controller
+}
+
+// continues the search of a checkpoint if it was made by the same algorithm, otherwise the
+// algorithm starts from the shortest cycle of the checkpoint
+func resume(alg algorithm.Algorithm, algorithmName string, checkpoint Checkpoint) {
+	if checkpoint.Cycle != nil {
+		if warmStarter, ok := alg.(algorithm.WarmStarter); ok {
+			warmStarter.SetInitialCycle(checkpoint.Cycle)
+		} else {
+			log.Printf("%s does not start from a given tour, starting from scratch", alg)
+		}
+	}
+
+	if checkpoint.State == nil {
+		return
+	}
+	checkpointer, ok := alg.(algorithm.Checkpointer)
+	if !ok || algorithmName != checkpoint.Algorithm {
+		log.Printf("the state of %s cannot be continued by %s, ignoring it", checkpoint.Algorithm, alg)
+		return
+	}
+	if err := checkpointer.Restore(checkpoint.State); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func (c *CliController) Start() {
 	c.running = true
 	c.startTime = time.Now()
-	updates := make(chan problem.Cycle, 10)
-	go c.algorithm.Solve(c.problem.Adjacency, updates)
+	c.improvedAt = time.Now()
+	c.lastCheckpoint = time.Now()
+	c.stopReason = ""
+	updates := make(chan algorithm.Progress, 10)
+
+	// an interrupted run is cancelled like a run that met a stopping criterion, so that its last
+	// checkpoint contains the state of the search
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	// the algorithm is cancelled when the controller returns, done receives the result of Solve
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.algorithm.Solve(ctx, c.problem.Adjacency, updates)
+	}()
+
+	// lower bounds are calculated alongside the algorithm
+	bounds := make(chan float64, 2)
+	go calculateLowerBounds(ctx, c.problem.Adjacency, bounds)
 
 	// ticker to update stats every second
 	ticker := time.NewTicker(1 * time.Second)
 
 	// immediately send status
-	status := problem.Status{
-		Algorithm:   c.algorithm.String(),
-		Problem:     c.problem.Info.Name,
-		Description: c.problem.Info.Description,
-		Elapsed:     time.Since(c.startTime).String(),
-		Shortest:    math.Round(c.problem.ShortestDistance*100) / 100,
-		Running:     true,
-	}
-
 	if c.webHandler != nil {
-		c.webHandler.Status <- status
+		c.webHandler.Status <- c.status()
 	}
 
 	for c.running {
+		// cancel the algorithm once a stopping criterion is met and wait for it to return
+		if c.stopReason == "" {
+			if reason := c.criteria.check(c.runState()); reason != "" {
+				c.stopReason = reason
+				log.Printf("Stopping %s: %s", c.algorithm, reason)
+				cancel()
+			}
+		}
+
 		select {
-		case update, more := <-updates:
+		case progress, more := <-updates:
 			if !more {
 				c.running = false
-				log.Printf("Finished execution of problemset \"%s\":\n\tRoute: %v\n\tDistance: %f\n\tTime: %fs\n",
+
+				// updates is closed by Solve right before it returns
+				if err := <-done; err != nil && err != context.Canceled {
+					log.Printf("%s failed: %v", c.algorithm, err)
+				}
+
+				// the shortest cycle of an exact algorithm is its own lower bound
+				if c.progress.Final && c.progress.Optimal {
+					c.lowerBound = c.problem.ShortestDistance
+				}
+				if c.stopReason == "" {
+					c.stopReason = "algorithm finished"
+				}
+				if len(c.checkpoints.Path) != 0 {
+					c.writeCheckpoint(c.progress.State)
+				}
+
+				log.Printf("Finished execution of problemset \"%s\":\n\tStopped: %s\n\tRoute: %v\n\tDistance: %f\n\tLower bound: %f\n\tGap: %.2f%%\n\tProgress: %s\n\tSeed: %d\n\tTime: %fs\n",
 					c.problem.Info.Name,
+					c.stopReason,
 					c.problem.ShortestRoute,
 					c.problem.ShortestDistance,
-					time.Since(c.startTime).Seconds(),
+					c.lowerBound,
+					algorithm.Gap(c.problem.ShortestDistance, c.lowerBound),
+					formatProgress(c.progress),
+					c.seed,
+					c.elapsed().Seconds(),
 				)
+				if c.webHandler != nil {
+					c.webHandler.Status <- c.status()
+				}
 				break
 			}
-			c.problem.UpdateRoute(update)
+			c.progress = progress
+			c.lowerBound = math.Max(c.lowerBound, progress.LowerBound)
+
+			// the cycle of an event with a state is part of the checkpoint
+			if progress.Cycle != nil {
+				c.cycle = progress.Cycle
+			}
+			if progress.State != nil && !progress.Final {
+				c.writeCheckpoint(progress.State)
+			}
+
+			// events without a cycle only report the progress of the algorithm
+			if progress.Cycle == nil {
+				if !progress.Final && progress.State == nil {
+					log.Printf("Progress: %s", formatProgress(progress))
+				}
+				continue
+			}
+
+			c.problem.SetRoute(progress.Cycle, progress.Distance)
+			c.improvedAt = time.Now()
 			log.Printf("New Route:\n\tRoute: %v\n\tDistance: %f\n", c.problem.ShortestRoute, c.problem.ShortestDistance)
 			if c.webHandler != nil {
 				coordinates := c.problem.MapRouteToImageCoordinates()
 				c.webHandler.Updates <- coordinates
 			}
+		case bound := <-bounds:
+			c.lowerBound = math.Max(c.lowerBound, bound)
+			log.Printf("Lower bound: %f, gap: %.2f%%", c.lowerBound, algorithm.Gap(c.problem.ShortestDistance, c.lowerBound))
+		case pheromones := <-c.pheromones:
+			select {
+			case c.webHandler.Pheromones <- c.pheromoneEdges(pheromones):
+			default:
+			}
+		case <-interrupts:
+			if c.stopReason == "" {
+				c.stopReason = "interrupted"
+				log.Printf("Stopping %s: interrupted", c.algorithm)
+				cancel()
+			}
 		case <-ticker.C:
+			// algorithms without a state only checkpoint their shortest cycle
+			if _, ok := c.algorithm.(algorithm.Checkpointer); !ok && len(c.checkpoints.Path) != 0 &&
+				time.Since(c.lastCheckpoint) >= c.checkpoints.Interval {
+				c.writeCheckpoint(nil)
+			}
+
 			if c.webHandler == nil {
 				continue
 			}
-			status := problem.Status{
-				Algorithm:   c.algorithm.String(),
-				Problem:     c.problem.Info.Name,
-				Description: c.problem.Info.Description,
-				Elapsed:     time.Since(c.startTime).String(),
-				Shortest:    math.Round(c.problem.ShortestDistance*100) / 100,
-				Running:     true,
-			}
-			c.webHandler.Status <- status
+			c.webHandler.Status <- c.status()
 		case <-time.After(100 * time.Millisecond):
 			break
 		}
@@ -106,3 +339,133 @@ func (c *CliController) Start() {
 
 	ticker.Stop()
 }
+
+// writes the shortest cycle, the progress and the given state of the search to the checkpoint file
+func (c *CliController) writeCheckpoint(state json.RawMessage) {
+	c.lastCheckpoint = time.Now()
+	checkpoint := Checkpoint{
+		ProblemHash: c.problem.Hash(),
+		Algorithm:   c.algorithmName,
+		Order:       c.problem.Order(),
+		Elapsed:     c.elapsed().Seconds(),
+		Cycle:       c.cycle,
+		Distance:    c.problem.ShortestDistance,
+		Iteration:   c.progress.Iteration,
+		State:       state,
+	}
+
+	if err := WriteCheckpoint(c.checkpoints.Path, checkpoint); err != nil {
+		log.Printf("failed to write checkpoint %s: %v", c.checkpoints.Path, err)
+		return
+	}
+	log.Printf("Checkpoint written to %s", c.checkpoints.Path)
+}
+
+// returns the time the run has been running, including the time of the run it resumed
+func (c *CliController) elapsed() time.Duration {
+	return time.Since(c.startTime) + c.resumedElapsed
+}
+
+// returns the state of the run that is checked against the stopping criteria
+func (c *CliController) runState() runState {
+	shortest := math.MaxFloat64
+	if c.problem.ShortestRoute != nil {
+		shortest = c.problem.ShortestDistance
+	}
+
+	return runState{
+		elapsed:          time.Since(c.startTime),
+		sinceImprovement: time.Since(c.improvedAt),
+		iteration:        c.progress.Iteration,
+		shortest:         shortest,
+		lowerBound:       c.lowerBound,
+	}
+}
+
+// returns the current status of the run, including the lower bound and the gap
+func (c *CliController) status() problem.Status {
+	return problem.Status{
+		Algorithm:   c.algorithm.String(),
+		Problem:     c.problem.Info.Name,
+		Description: c.problem.Info.Description,
+		Elapsed:     c.elapsed().String(),
+		Shortest:    math.Round(c.problem.ShortestDistance*100) / 100,
+		Running:     c.running,
+		LowerBound:  math.Round(c.lowerBound*100) / 100,
+		Gap:         math.Round(algorithm.Gap(c.problem.ShortestDistance, c.lowerBound)*100) / 100,
+		Iteration:   c.progress.Iteration,
+		Phase:       c.progress.Phase,
+		Stats:       c.progress.Stats,
+	}
+}
+
+// formats the iteration, the phase and the statistics of a progress event for the log,
+// e.g. "iteration 4200, temperature: 12.5"
+func formatProgress(progress algorithm.Progress) string {
+	parts := []string{fmt.Sprintf("iteration %d", progress.Iteration)}
+	if progress.Phase != "" {
+		parts = append(parts, progress.Phase)
+	}
+
+	keys := make([]string, 0, len(progress.Stats))
+	for key := range progress.Stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := strconv.FormatFloat(math.Round(progress.Stats[key]*100)/100, 'f', -1, 64)
+		parts = append(parts, key+": "+value)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// sends the trivial minimum spanning tree bound right away, followed by the tighter held-karp bound.
+// the bounds of asymmetric problems are the bounds of the shorter direction of every edge, which no
+// cycle can beat in either direction. stops when ctx is cancelled, e.g. because the algorithm finished
+func calculateLowerBounds(ctx context.Context, adjacency problem.Adjacency, bounds chan<- float64) {
+	if !adjacency.Symmetric() {
+		shorter := make(problem.Adjacency, len(adjacency))
+		for i := range shorter {
+			shorter[i] = make([]float64, len(adjacency))
+			for j := range shorter[i] {
+				shorter[i][j] = math.Min(adjacency[i][j], adjacency[j][i])
+			}
+		}
+		adjacency = shorter
+	}
+
+	bounds <- algorithm.MinimumSpanningTreeBound(adjacency)
+
+	upperBound := adjacency.Distance(algorithm.NearestNeighbour(adjacency, 0))
+	bound := algorithm.HeldKarpBound(ctx, adjacency, upperBound)
+	if ctx.Err() == nil {
+		bounds <- bound
+	}
+}
+
+// converts a pheromone-matrix to edges on the image of the problem, intensities are relative to the strongest edge
+func (c *CliController) pheromoneEdges(pheromones [][]float64) web.PheromonesMessageData {
+	coordinates := c.problem.MapPointsToImageCoordinates()
+
+	var strongest float64
+	for i := range pheromones {
+		for j := i + 1; j < len(pheromones); j++ {
+			strongest = math.Max(strongest, pheromones[i][j])
+		}
+	}
+
+	data := web.PheromonesMessageData{Edges: make([]int, 0), Intensities: make([]float64, 0)}
+	for i := range pheromones {
+		for j := i + 1; j < len(pheromones); j++ {
+			intensity := pheromones[i][j] / strongest
+			if intensity < pheromoneThreshold {
+				continue
+			}
+			data.Edges = append(data.Edges, coordinates[2*i], coordinates[2*i+1], coordinates[2*j], coordinates[2*j+1])
+			data.Intensities = append(data.Intensities, intensity)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,54 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"leistungsnachweis-graphiker/algorithm"
+)
+
+// rules that end a run before the algorithm finishes, a zero value disables a rule
+type StoppingCriteria struct {
+	// wall-clock limit for the whole run
+	TimeLimit time.Duration
+
+	// limit for the iterations reported by the algorithm, e.g. kicks or generations
+	Iterations int
+
+	// stop when the shortest cycle exceeds the lower bound by at most this many percent
+	Gap float64
+
+	// stop as soon as a cycle is at least as short as this distance
+	Target float64
+
+	// stop when the shortest cycle did not improve for this long
+	NoImprovement time.Duration
+}
+
+// the state of a run that the stopping criteria are checked against
+type runState struct {
+	elapsed          time.Duration
+	sinceImprovement time.Duration
+	iteration        int
+	shortest         float64 // math.MaxFloat64 if there is no cycle yet
+	lowerBound       float64
+}
+
+// returns a description of the first criterion that is met, an empty string if the run continues
+func (s StoppingCriteria) check(state runState) string {
+	switch {
+	case s.TimeLimit > 0 && state.elapsed >= s.TimeLimit:
+		return fmt.Sprintf("time limit of %s reached", s.TimeLimit)
+	case s.Iterations > 0 && state.iteration >= s.Iterations:
+		return fmt.Sprintf("iteration limit of %d reached", s.Iterations)
+	case s.Gap > 0 && state.lowerBound > 0 && state.shortest != math.MaxFloat64 &&
+		algorithm.Gap(state.shortest, state.lowerBound) <= s.Gap:
+		return fmt.Sprintf("within %.2f%% of the lower bound", s.Gap)
+	case s.Target > 0 && state.shortest <= s.Target:
+		return fmt.Sprintf("target distance of %g reached", s.Target)
+	case s.NoImprovement > 0 && state.sinceImprovement >= s.NoImprovement:
+		return fmt.Sprintf("no improvement for %s", s.NoImprovement)
+	}
+	return ""
+}
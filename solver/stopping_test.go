@@ -0,0 +1,61 @@
+package solver
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"leistungsnachweis-graphiker/problem"
+)
+
+func TestStoppingCriteria(t *testing.T) {
+	running := runState{
+		elapsed:          time.Second,
+		sinceImprovement: 500 * time.Millisecond,
+		iteration:        100,
+		shortest:         110,
+		lowerBound:       100,
+	}
+
+	tests := []struct {
+		criteria StoppingCriteria
+		state    runState
+		reason   string
+	}{
+		{StoppingCriteria{}, running, ""},
+		{StoppingCriteria{TimeLimit: time.Second}, running, "time limit"},
+		{StoppingCriteria{TimeLimit: 2 * time.Second}, running, ""},
+		{StoppingCriteria{Iterations: 100}, running, "iteration limit"},
+		{StoppingCriteria{Iterations: 101}, running, ""},
+		{StoppingCriteria{Gap: 10}, running, "lower bound"},
+		{StoppingCriteria{Gap: 5}, running, ""},
+		{StoppingCriteria{Gap: 10}, runState{shortest: math.MaxFloat64, lowerBound: 100}, ""},
+		{StoppingCriteria{Target: 110}, running, "target distance"},
+		{StoppingCriteria{Target: 109}, running, ""},
+		{StoppingCriteria{Target: 110}, runState{shortest: math.MaxFloat64}, ""},
+		{StoppingCriteria{NoImprovement: 500 * time.Millisecond}, running, "no improvement"},
+		{StoppingCriteria{NoImprovement: time.Second}, running, ""},
+	}
+
+	for _, test := range tests {
+		reason := test.criteria.check(test.state)
+		if (test.reason == "") != (reason == "") || !strings.Contains(reason, test.reason) {
+			t.Fatalf("%+v: expected reason containing %q, got %q", test.criteria, test.reason, reason)
+		}
+	}
+}
+
+func TestStoppingResumedTimeLimit(t *testing.T) {
+	// the time of the resumed run is reported, but does not count towards the time limit
+	criteria := StoppingCriteria{TimeLimit: time.Minute}
+	c := newCliController(nil, problem.Problem{}, criteria, "bruteforce", CheckpointConfig{}, &Checkpoint{Elapsed: 3600})
+	c.startTime = time.Now()
+
+	if reason := criteria.check(c.runState()); reason != "" {
+		t.Fatalf("resumed run stopped right away: %s", reason)
+	}
+	if elapsed := c.elapsed(); elapsed < time.Hour {
+		t.Fatalf("elapsed time without the resumed run: %s", elapsed)
+	}
+}
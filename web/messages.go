@@ -6,6 +6,7 @@ const (
 	PostImage   = "PostImage"
 	Coordinates = "Coordinates"
 	Status      = "Status"
+	Pheromones  = "Pheromones"
 )
 
 type Message struct {
@@ -24,3 +25,10 @@ type CoordinatesMessageData struct {
 type StatusMessageData struct {
 	Status problem.Status `json:"status"`
 }
+
+// edges of a pheromone-matrix, as pairs of coordinates x1, y1, x2, y2 and the intensity of each
+// edge between 0 and 1. used to render a heat-map of the pheromones
+type PheromonesMessageData struct {
+	Edges       []int     `json:"edges"`
+	Intensities []float64 `json:"intensities"`
+}
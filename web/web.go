@@ -21,6 +21,7 @@ type Handler struct {
 	sync        sync.Mutex
 	Updates     chan []int
 	Status      chan problem.Status
+	Pheromones  chan PheromonesMessageData
 }
 
 func NewHandler(image, bind string) (*Handler, error) {
@@ -44,6 +45,7 @@ func NewHandler(image, bind string) (*Handler, error) {
 		sync:        sync.Mutex{},
 		Updates:     make(chan []int, 100),
 		Status:      make(chan problem.Status, 10),
+		Pheromones:  make(chan PheromonesMessageData, 1),
 	}
 
 	go wh.startListen()
@@ -109,6 +111,8 @@ func (wh *Handler) processUpdates() {
 			time.Sleep(100 * time.Millisecond) // hack, to prevent frontend to draw too fast
 		case status := <-wh.Status:
 			wh.sendStatus(status)
+		case pheromones := <-wh.Pheromones:
+			wh.sendPheromones(pheromones)
 		case <-time.After(100 * time.Millisecond):
 			break
 		}
@@ -137,6 +141,17 @@ func (wh *Handler) sendStatus(status problem.Status) {
 	}
 }
 
+func (wh *Handler) sendPheromones(pheromones PheromonesMessageData) {
+	for _, conn := range wh.connections {
+		msg := Message{Type: Pheromones, Data: pheromones}
+		err := conn.WriteJSON(msg)
+
+		if err != nil {
+			wh.removeConnection(conn)
+		}
+	}
+}
+
 func checkOriginTrue(_ *http.Request) bool {
 	return true
 }
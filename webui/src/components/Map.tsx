@@ -1,11 +1,12 @@
 import React from 'react';
 import {connect} from "react-redux";
-import {AppState} from "../redux/AppState";
+import {AppState, Pheromones} from "../redux/AppState";
 import Spinner from "./Spinner";
 
 interface MapProps {
     image: string,
     points: number[],
+    pheromones: Pheromones,
 }
 
 class ImageLoader {
@@ -30,7 +31,7 @@ class ImageLoader {
 
 const imageLoader = new ImageLoader();
 
-const Canvas : React.FunctionComponent<MapProps> = ({image, points}) => {
+const Canvas : React.FunctionComponent<MapProps> = ({image, points, pheromones}) => {
     const img = new Image();
     img.src = "data:image/gif;base64," + image;
 
@@ -41,6 +42,16 @@ const Canvas : React.FunctionComponent<MapProps> = ({image, points}) => {
 
     const scaling = 620 / img.height;
 
+    // heat-map of the pheromones of ant colony optimization, stronger edges are more opaque
+    const trails = [];
+    for (let i = 0; i < pheromones.intensities.length; i++) {
+        let x1 = pheromones.edges[4*i] * scaling;
+        let y1 = pheromones.edges[4*i+1] * scaling;
+        let x2 = pheromones.edges[4*i+2] * scaling;
+        let y2 = pheromones.edges[4*i+3] * scaling;
+        trails.push(<line x1={x1} x2={x2} y1={y1} y2={y2} stroke={"orange"} strokeWidth={"3"} strokeOpacity={pheromones.intensities[i]}/>)
+    }
+
     const lines = [];
     for (let i = 0; i < actualPoints.length; i++) {
         if (i === actualPoints.length-1) {
@@ -62,6 +73,7 @@ const Canvas : React.FunctionComponent<MapProps> = ({image, points}) => {
         <div className={"m-auto j-image-container"}>
             <div className={"col j-image"}>
                 <svg width={img.width * scaling} height={620} xmlns="http://www.w3.org/2000/svg" version="1.1">
+                    {trails}
                     {lines}
                 </svg>
                 <img src={"data:image/gif;base64," + image} alt={"Not available."}/>
@@ -69,10 +81,10 @@ const Canvas : React.FunctionComponent<MapProps> = ({image, points}) => {
            </div>);
 };
 
-const CanvasContainer : React.FunctionComponent<MapProps> = ({image, points}) => {
+const CanvasContainer : React.FunctionComponent<MapProps> = ({image, points, pheromones}) => {
     const actualImage = image.length === 0 ?
         <div className={"m-auto"}><Spinner text={""}/></div> :
-        <Canvas image={image} points={points}/>;
+        <Canvas image={image} points={points} pheromones={pheromones}/>;
 
     if (image.length > 0 && !imageLoader.isInitialized()) {
         imageLoader.setImage(image);
@@ -85,7 +97,7 @@ const CanvasContainer : React.FunctionComponent<MapProps> = ({image, points}) =>
 };
 
 const mapStateToProps = (state: AppState) => {
-    return {image: state.image, points: state.points}
+    return {image: state.image, points: state.points, pheromones: state.pheromones}
 };
 
 export default connect(mapStateToProps)(CanvasContainer);
\ No newline at end of file
@@ -3,7 +3,7 @@ import {connect} from "react-redux";
 import {AppState, Status} from "../redux/AppState";
 import Spinner from "./Spinner";
 
-const InfoPanel : React.FunctionComponent<Status> = ({algorithm, problem, description, running, elapsed, shortest}) => {
+const InfoPanel : React.FunctionComponent<Status> = ({algorithm, problem, description, running, elapsed, shortest, lowerBound, gap, iteration, phase, stats}) => {
     let content = <div className={"ml-auto mr-auto"}><Spinner text={""}/></div>;
 
     // if we haven't received any data yet, show empty
@@ -18,7 +18,14 @@ const InfoPanel : React.FunctionComponent<Status> = ({algorithm, problem, descri
             <h4>Elapsed:</h4>
             <h5>{elapsed}</h5>
             <h4>Shortest:</h4>
-            <h5 className={"pb-0"}>{shortest}</h5>
+            <h5>{shortest}</h5>
+            <h4>Lower bound:</h4>
+            <h5>{lowerBound} ({gap}% gap)</h5>
+            <h4>Progress:</h4>
+            <h5 className={"pb-0"}>iteration {iteration}{phase.length !== 0 ? ", " + phase : ""}</h5>
+            {Object.keys(stats || {}).sort().map(key =>
+                <h5 key={key} className={"pb-0"}>{key}: {Math.round(stats![key] * 100) / 100}</h5>
+            )}
         </div>;
     }
 
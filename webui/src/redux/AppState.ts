@@ -11,6 +11,7 @@ export enum ActionTypes {
     ON_POST_IMAGE = "ON_POST_IMAGE",
     ON_POST_COORDINATES = "ON_POST_COORDINATES",
     ON_POST_STATUS = "ON_POST_STATUS",
+    ON_POST_PHEROMONES = "ON_POST_PHEROMONES",
 }
 
 export interface OnInit { type: string }
@@ -31,6 +32,9 @@ export const onPostCoordinates = (coordinates: number[]) => { return {type: Acti
 export interface OnPostStatus { type: string, status: Status }
 export const onPostStatus = (status: Status) => { return {type: ActionTypes.ON_POST_STATUS, status} };
 
+export interface OnPostPheromones { type: string, pheromones: Pheromones }
+export const onPostPheromones = (pheromones: Pheromones) => { return {type: ActionTypes.ON_POST_PHEROMONES, pheromones} };
+
 //**********************************************************
 // Messages
 //**********************************************************
@@ -38,6 +42,7 @@ export enum MessageTypes {
     POST_IMAGE = "PostImage",
     COORDINATES = "Coordinates",
     STATUS = "Status",
+    PHEROMONES = "Pheromones",
 }
 
 export interface ImageMessageData {
@@ -52,6 +57,11 @@ export interface StatusMessageData {
     status: Status;
 }
 
+export interface PheromonesMessageData {
+    edges: number[];
+    intensities: number[];
+}
+
 //**********************************************************
 // Types
 //**********************************************************
@@ -59,6 +69,7 @@ export interface AppState {
     connected: boolean;
     image: string;
     points: number[];
+    pheromones: Pheromones;
     settings: Settings;
     status: Status;
 }
@@ -67,6 +78,12 @@ export interface Settings {
     server: string;
 }
 
+// edges as pairs of coordinates x1, y1, x2, y2 and the intensity of each edge between 0 and 1
+export interface Pheromones {
+    edges: number[];
+    intensities: number[];
+}
+
 export interface Message {
     type: string;
     data: object;
@@ -79,6 +96,11 @@ export interface Status {
     elapsed: string;
     shortest: number;
     running: boolean;
+    lowerBound: number;
+    gap: number;
+    iteration: number;
+    phase: string;
+    stats: {[key: string]: number} | null;
 }
 
 //**********************************************************
@@ -88,8 +110,9 @@ const initialState: AppState = {
     connected: false,
     image: "",
     points: [],
+    pheromones: {edges: [], intensities: []},
     settings: {server: "ws://localhost:8091/websocket/"},
-    status: {algorithm: "", problem: "", description: "", elapsed: "", running: false, shortest: 0},
+    status: {algorithm: "", problem: "", description: "", elapsed: "", running: false, shortest: 0, lowerBound: 0, gap: 0, iteration: 0, phase: "", stats: null},
 };
 
 const reducer: Reducer<AppState> = (state = initialState, action) => {
@@ -104,6 +127,8 @@ const reducer: Reducer<AppState> = (state = initialState, action) => {
             return Object.assign({}, state, {points: (action as OnPostCoordinates).coordinates});
         case ActionTypes.ON_POST_STATUS:
             return Object.assign({}, state, {status: (action as OnPostStatus).status });
+        case ActionTypes.ON_POST_PHEROMONES:
+            return Object.assign({}, state, {pheromones: (action as OnPostPheromones).pheromones});
         default:
             break;
     }
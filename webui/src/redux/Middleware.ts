@@ -8,7 +8,7 @@ import {
     MessageTypes,
     onConnect,
     onDisconnect, onPostCoordinates,
-    onPostImage, onPostStatus, StatusMessageData
+    onPostImage, onPostPheromones, onPostStatus, PheromonesMessageData, StatusMessageData
 } from "./AppState";
 
 let _websocket: JWebSocket;
@@ -42,6 +42,10 @@ export function serverMiddleware() {
                         const statusMessageData = msg.data as StatusMessageData;
                         dispatch(onPostStatus(statusMessageData.status));
                         break;
+                    case MessageTypes.PHEROMONES:
+                        const pheromonesMessageData = msg.data as PheromonesMessageData;
+                        dispatch(onPostPheromones(pheromonesMessageData));
+                        break;
                     default:
                         break;
                 }